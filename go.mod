@@ -11,10 +11,19 @@ require (
 	github.com/go-openapi/spec v0.19.9
 	github.com/go-openapi/strfmt v0.19.5
 	github.com/go-openapi/swag v0.19.9
+	github.com/go-openapi/validate v0.19.10
 	github.com/huandu/facebook/v2 v2.5.2
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/joho/godotenv v1.3.0
+	github.com/prometheus/client_golang v1.8.0
 	github.com/robfig/cron/v3 v3.0.1
+	go.etcd.io/bbolt v1.3.5
+	go.opentelemetry.io/otel v0.16.0
+	go.opentelemetry.io/otel/exporters/otlp v0.16.0
+	go.opentelemetry.io/otel/exporters/stdout v0.16.0
+	go.opentelemetry.io/otel/sdk v0.16.0
 	go.uber.org/zap v1.16.0
 	golang.org/x/net v0.0.0-20200930145003-4acb6c075d10
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -1,39 +1,468 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	oaerrors "github.com/go-openapi/errors"
 	"github.com/go-openapi/loads"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/swagger/server/models"
 	"github.com/lttkgp/R2-D2/pkg/swagger/server/restapi"
 	"github.com/lttkgp/R2-D2/pkg/swagger/server/restapi/operations"
 	"go.uber.org/zap"
 )
 
-func initializeAPIServer(logger *zap.Logger) {
-	// Initialize Swagger
+// newAPI creates the Swagger API with all route handlers registered, dispatching posts right away with dispatcher
+func newAPI(ctx context.Context, store PostStore, dispatcher *Dispatcher, logger *zap.Logger) (*operations.R2d2API, error) {
+	return newAPIWithBackfill(store, dispatcher, &backfillRunner{ctx: ctx, backfill: Backfill}, logger)
+}
+
+// newAPIWithBackfill creates the Swagger API, starting backfills with the given runner
+func newAPIWithBackfill(store PostStore, dispatcher *Dispatcher, backfills *backfillRunner, logger *zap.Logger) (*operations.R2d2API, error) {
 	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
 	if err != nil {
-		logger.Fatal("Failed to parse Swagger config", zap.Error(err))
+		return nil, err
 	}
 
 	api := operations.NewR2d2API(swaggerSpec)
-	server := restapi.NewServer(api)
-
-	defer func() {
-		if err := server.Shutdown(); err != nil {
-			logger.Fatal("Failed to gracefully shut down the server", zap.Error(err))
-		}
-	}()
+	api.AdminTokenAuth = adminTokenAuth
+	api.CheckHealthHandler = operations.CheckHealthHandlerFunc(Health)
+	api.ListPostsHandler = ListPostsHandler(store, logger)
+	api.GetPostHandler = GetPostHandler(store, logger)
+	api.ListEngagementSnapshotsHandler = ListEngagementSnapshotsHandler(store, logger)
+	api.RedispatchPostHandler = RedispatchPostHandler(store, dispatcher, logger)
+	api.RedispatchPostsHandler = RedispatchPostsHandler(store, dispatcher, logger)
+	api.ReplayDeadLettersHandler = ReplayDeadLettersHandler(store, dispatcher, logger)
+	api.GetBackfillHandler = GetBackfillHandler(store, backfills, logger)
+	api.StartBackfillHandler = StartBackfillHandler(store, backfills, logger)
+	api.VerifyFacebookWebhookHandler = VerifyFacebookWebhookHandler(logger)
+	api.ReceiveFacebookWebhookHandler = ReceiveFacebookWebhookHandler(store, getFacebookSession, backfills, logger)
+	return api, nil
+}
 
+// initializeAPIServer serves the API until the process is asked to stop
+func initializeAPIServer(store PostStore, dispatcher *Dispatcher, backfills *backfillRunner, beforeShutdown func(), logger *zap.Logger) error {
+	// Initialize Swagger
+	api, err := newAPIWithBackfill(store, dispatcher, backfills, logger)
+	if err != nil {
+		return fmt.Errorf("parsing Swagger config: %w", err)
+	}
+	// The server stops on SIGINT or SIGTERM, calling beforeShutdown first, then gives in-flight requests up to
+	// SHUTDOWN_TIMEOUT seconds to finish
+	api.PreServerShutdown = beforeShutdown
+	server := restapi.NewServer(api)
+	server.SetHandler(serveMetrics(server.GetHandler()))
+	server.GracefulTimeout = time.Duration(config.ShutdownTimeout) * time.Second
 	server.Port = 8080
-	api.CheckHealthHandler = operations.CheckHealthHandlerFunc(Health)
 
 	// Start server
-	if err := server.Serve(); err != nil {
-		logger.Fatal("Unable to start the API server", zap.Error(err))
+	return server.Serve()
+}
+
+// adminTokenAuth authenticates requests to the admin endpoints by their `X-Admin-Token` header. Every request is
+// rejected while `ADMIN_TOKEN` is unset.
+func adminTokenAuth(token string) (interface{}, error) {
+	if config.AdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) != 1 {
+		return nil, oaerrors.Unauthenticated("AdminToken")
 	}
+	return "admin", nil
 }
 
 //Health route returns OK
 func Health(operations.CheckHealthParams) middleware.Responder {
 	return operations.NewCheckHealthOK().WithPayload("OK")
 }
+
+// newErrorModel wraps a message in the API error model
+func newErrorModel(message string) *models.Error {
+	return &models.Error{Message: &message}
+}
+
+// newPostModel converts a stored post to its API representation, with the dispatch state of its C-3PO delivery.
+// Posts without one were stored before deliveries were tracked, and count as parsed.
+func newPostModel(postData PostData, deliveries []WebhookDelivery) *models.Post {
+	post := &models.Post{
+		CreatedTime:     strfmt.DateTime(postData.CreatedTime),
+		DispatchHistory: make([]*models.DispatchAttempt, 0, len(postData.DispatchHistory)),
+		FacebookID:      postData.FacebookID,
+		IsParsed:        true,
+		Post:            postData.FacebookPost,
+		UpdatedTime:     strfmt.DateTime(postData.UpdatedTime),
+	}
+	if postData.DeletedTime != nil {
+		deletedTime := strfmt.DateTime(*postData.DeletedTime)
+		post.DeletedTime = &deletedTime
+	}
+	for _, delivery := range deliveries {
+		if delivery.Subscriber != c3poSubscriberName {
+			continue
+		}
+		post.IsParsed = delivery.Status == webhookDeliveryDelivered
+		post.DeadLettered = delivery.Status == webhookDeliveryFailed
+		post.DispatchAttempts = int64(delivery.Attempts)
+		post.LastDispatchError = delivery.LastError
+		if delivery.NextAttemptTime != nil {
+			nextDispatchTime := strfmt.DateTime(*delivery.NextAttemptTime)
+			post.NextDispatchTime = &nextDispatchTime
+		}
+	}
+	for _, link := range postData.Links {
+		post.Links = append(post.Links, &models.MusicLink{
+			ID:       link.ID,
+			Provider: link.Provider,
+			Source:   link.Source,
+			URL:      link.URL,
+		})
+	}
+	for _, dispatchRecord := range postData.DispatchHistory {
+		post.DispatchHistory = append(post.DispatchHistory, &models.DispatchAttempt{
+			DispatchedAt: strfmt.DateTime(dispatchRecord.DispatchedAt),
+			Error:        dispatchRecord.Error,
+			Success:      dispatchRecord.Success,
+		})
+	}
+	return post
+}
+
+// newDeliveryModel converts a webhook delivery to its API representation
+func newDeliveryModel(delivery WebhookDelivery) *models.Delivery {
+	deliveryModel := &models.Delivery{
+		Attempts:   int64(delivery.Attempts),
+		Event:      delivery.Event,
+		LastError:  delivery.LastError,
+		Status:     delivery.Status,
+		Subscriber: delivery.Subscriber,
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := strfmt.DateTime(*delivery.DeliveredAt)
+		deliveryModel.DeliveredAt = &deliveredAt
+	}
+	if delivery.NextAttemptTime != nil {
+		nextAttemptTime := strfmt.DateTime(*delivery.NextAttemptTime)
+		deliveryModel.NextAttemptTime = &nextAttemptTime
+	}
+	return deliveryModel
+}
+
+// ListPostsHandler route returns a page of stored posts
+func ListPostsHandler(store PostStore, logger *zap.Logger) operations.ListPostsHandlerFunc {
+	return func(params operations.ListPostsParams) middleware.Responder {
+		query := PostQuery{IsParsed: params.IsParsed, DeadLettered: *params.DeadLettered, Limit: int(*params.Limit)}
+		if params.Cursor != nil {
+			query.Cursor = *params.Cursor
+		}
+		if params.CreatedAfter != nil {
+			query.CreatedAfter = time.Time(*params.CreatedAfter)
+		}
+		if params.CreatedBefore != nil {
+			query.CreatedBefore = time.Time(*params.CreatedBefore)
+		}
+
+		page, err := store.ListPosts(params.HTTPRequest.Context(), query)
+		if errors.Is(err, ErrInvalidCursor) {
+			return operations.NewListPostsBadRequest().WithPayload(newErrorModel(err.Error()))
+		}
+		if err != nil {
+			logger.Error("Failed to list posts", zap.Error(err))
+			return operations.NewListPostsInternalServerError().WithPayload(newErrorModel("failed to list posts"))
+		}
+
+		postList := &models.PostList{NextCursor: page.NextCursor, Posts: make([]*models.Post, 0, len(page.Posts))}
+		for _, postData := range page.Posts {
+			deliveries, err := store.ListWebhookDeliveries(params.HTTPRequest.Context(), postData.FacebookID)
+			if err != nil {
+				logger.Error("Failed to list webhook deliveries", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+				return operations.NewListPostsInternalServerError().WithPayload(newErrorModel("failed to list webhook deliveries"))
+			}
+			postList.Posts = append(postList.Posts, newPostModel(postData, deliveries))
+		}
+		return operations.NewListPostsOK().WithPayload(postList)
+	}
+}
+
+// GetPostHandler route returns a single stored post with its dispatch history and delivery status per subscriber,
+// C-3PO included
+func GetPostHandler(store PostStore, logger *zap.Logger) operations.GetPostHandlerFunc {
+	return func(params operations.GetPostParams) middleware.Responder {
+		postData, err := store.GetPost(params.HTTPRequest.Context(), params.FacebookID)
+		if errors.Is(err, ErrPostNotFound) {
+			return operations.NewGetPostNotFound().WithPayload(newErrorModel(err.Error()))
+		}
+		if err != nil {
+			logger.Error("Failed to get post", zap.String("FacebookID", params.FacebookID), zap.Error(err))
+			return operations.NewGetPostInternalServerError().WithPayload(newErrorModel("failed to get post"))
+		}
+		deliveries, err := store.ListWebhookDeliveries(params.HTTPRequest.Context(), params.FacebookID)
+		if err != nil {
+			logger.Error("Failed to list webhook deliveries", zap.String("FacebookID", params.FacebookID), zap.Error(err))
+			return operations.NewGetPostInternalServerError().WithPayload(newErrorModel("failed to list webhook deliveries"))
+		}
+
+		post := newPostModel(postData, deliveries)
+		post.Deliveries = make([]*models.Delivery, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			post.Deliveries = append(post.Deliveries, newDeliveryModel(delivery))
+		}
+		return operations.NewGetPostOK().WithPayload(post)
+	}
+}
+
+// ListEngagementSnapshotsHandler route returns the engagement samples of a post
+func ListEngagementSnapshotsHandler(store PostStore, logger *zap.Logger) operations.ListEngagementSnapshotsHandlerFunc {
+	return func(params operations.ListEngagementSnapshotsParams) middleware.Responder {
+		_, err := store.GetPost(params.HTTPRequest.Context(), params.FacebookID)
+		if errors.Is(err, ErrPostNotFound) {
+			return operations.NewListEngagementSnapshotsNotFound().WithPayload(newErrorModel(err.Error()))
+		}
+		if err != nil {
+			logger.Error("Failed to get post", zap.String("FacebookID", params.FacebookID), zap.Error(err))
+			return operations.NewListEngagementSnapshotsInternalServerError().WithPayload(newErrorModel("failed to get post"))
+		}
+
+		snapshots, err := store.ListEngagementSnapshots(params.HTTPRequest.Context(), params.FacebookID)
+		if err != nil {
+			logger.Error("Failed to list engagement snapshots", zap.String("FacebookID", params.FacebookID), zap.Error(err))
+			return operations.NewListEngagementSnapshotsInternalServerError().WithPayload(newErrorModel("failed to list engagement snapshots"))
+		}
+		snapshotList := &models.EngagementSnapshotList{Snapshots: make([]*models.EngagementSnapshot, 0, len(snapshots))}
+		for _, snapshot := range snapshots {
+			snapshotList.Snapshots = append(snapshotList.Snapshots, &models.EngagementSnapshot{
+				Comments:  snapshot.Comments,
+				Reactions: snapshot.Reactions,
+				SampledAt: strfmt.DateTime(snapshot.SampledAt),
+			})
+		}
+		return operations.NewListEngagementSnapshotsOK().WithPayload(snapshotList)
+	}
+}
+
+// replayDelivery queues a delivery again with a fresh count of attempts, and attempts it right away if immediate is
+// set. Deliveries to C-3PO are sent through the dispatcher, so that they share its rate limit.
+func replayDelivery(ctx context.Context, store PostStore, dispatcher *Dispatcher, delivery WebhookDelivery, immediate bool, logger *zap.Logger) *models.RedispatchResult {
+	result := &models.RedispatchResult{FacebookID: delivery.FacebookID, Subscriber: delivery.Subscriber}
+	delivery.Status = webhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttemptTime = nil
+	delivery.UpdatedAt = time.Now().UTC()
+
+	// Queue first, so that an attempt cut short leaves the delivery pending
+	if err := store.UpdateOrInsertWebhookDelivery(ctx, delivery); err != nil {
+		result.Status = models.RedispatchResultStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	if !immediate {
+		result.Status = models.RedispatchResultStatusQueued
+		return result
+	}
+
+	var err error
+	if delivery.Subscriber == c3poSubscriberName {
+		err = dispatcher.DispatchNow(ctx, delivery)
+	} else {
+		err = deliverWebhook(ctx, store, delivery, logger)
+	}
+	if err != nil {
+		result.Status = models.RedispatchResultStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = models.RedispatchResultStatusDispatched
+	return result
+}
+
+// redispatchPost queues the latest version of a post for C-3PO again, or sends it right away through the dispatcher
+// if immediate is set
+func redispatchPost(ctx context.Context, store PostStore, dispatcher *Dispatcher, postData PostData, immediate bool, logger *zap.Logger) *models.RedispatchResult {
+	if postData.DeletedTime != nil {
+		return &models.RedispatchResult{
+			Error:      "post was deleted from Facebook",
+			FacebookID: postData.FacebookID,
+			Status:     models.RedispatchResultStatusFailed,
+			Subscriber: c3poSubscriberName,
+		}
+	}
+	deliveries, err := store.ListWebhookDeliveries(ctx, postData.FacebookID)
+	if err != nil {
+		return &models.RedispatchResult{
+			Error:      err.Error(),
+			FacebookID: postData.FacebookID,
+			Status:     models.RedispatchResultStatusFailed,
+			Subscriber: c3poSubscriberName,
+		}
+	}
+
+	delivery := WebhookDelivery{FacebookID: postData.FacebookID, Subscriber: c3poSubscriberName, Event: webhookEventNew}
+	for _, previous := range deliveries {
+		// C-3PO already knows about posts it was sent before
+		if previous.Subscriber == c3poSubscriberName && previous.DeliveredAt != nil {
+			delivery.Event = webhookEventUpdated
+			delivery.DeliveredAt = previous.DeliveredAt
+		}
+	}
+	return replayDelivery(ctx, store, dispatcher, delivery, immediate, logger)
+}
+
+// RedispatchPostHandler route sends a single post to C-3PO again
+func RedispatchPostHandler(store PostStore, dispatcher *Dispatcher, logger *zap.Logger) operations.RedispatchPostHandlerFunc {
+	return func(params operations.RedispatchPostParams, _ interface{}) middleware.Responder {
+		postData, err := store.GetPost(params.HTTPRequest.Context(), params.FacebookID)
+		if errors.Is(err, ErrPostNotFound) {
+			return operations.NewRedispatchPostNotFound().WithPayload(newErrorModel(err.Error()))
+		}
+		if err != nil {
+			logger.Error("Failed to get post", zap.String("FacebookID", params.FacebookID), zap.Error(err))
+			return operations.NewRedispatchPostInternalServerError().WithPayload(newErrorModel("failed to get post"))
+		}
+		return operations.NewRedispatchPostOK().WithPayload(redispatchPost(params.HTTPRequest.Context(), store, dispatcher, postData, *params.Immediate, logger))
+	}
+}
+
+// RedispatchPostsHandler route sends every post created in a time range to C-3PO again
+func RedispatchPostsHandler(store PostStore, dispatcher *Dispatcher, logger *zap.Logger) operations.RedispatchPostsHandlerFunc {
+	return func(params operations.RedispatchPostsParams, _ interface{}) middleware.Responder {
+		query := PostQuery{CreatedAfter: time.Time(*params.Body.CreatedAfter), Limit: 100}
+		if !time.Time(params.Body.CreatedBefore).IsZero() {
+			query.CreatedBefore = time.Time(params.Body.CreatedBefore)
+		}
+
+		posts, err := listAllPosts(params.HTTPRequest.Context(), store, query)
+		if err != nil {
+			logger.Error("Failed to list posts for redispatch", zap.Error(err))
+			return operations.NewRedispatchPostsInternalServerError().WithPayload(newErrorModel("failed to list posts"))
+		}
+
+		report := &models.RedispatchReport{Results: make([]*models.RedispatchResult, 0, len(posts))}
+		for _, postData := range posts {
+			report.Results = append(report.Results, redispatchPost(params.HTTPRequest.Context(), store, dispatcher, postData, params.Body.Immediate, logger))
+		}
+		logger.Info("Redispatched posts", zap.Int("count", len(posts)), zap.Bool("immediate", params.Body.Immediate))
+		return operations.NewRedispatchPostsOK().WithPayload(report)
+	}
+}
+
+// ReplayDeadLettersHandler route queues every dead-lettered delivery again, to C-3PO and the webhook subscribers alike
+func ReplayDeadLettersHandler(store PostStore, dispatcher *Dispatcher, logger *zap.Logger) operations.ReplayDeadLettersHandlerFunc {
+	return func(params operations.ReplayDeadLettersParams, _ interface{}) middleware.Responder {
+		// Collect all dead letters first, since replaying them changes the status index
+		var deadLetters []WebhookDelivery
+		err := store.QueryWebhookDeliveries(params.HTTPRequest.Context(), webhookDeliveryFailed, func(delivery WebhookDelivery) bool {
+			deadLetters = append(deadLetters, delivery)
+			return true
+		})
+		if err != nil {
+			logger.Error("Failed to list dead-lettered deliveries", zap.Error(err))
+			return operations.NewReplayDeadLettersInternalServerError().WithPayload(newErrorModel("failed to list dead-lettered deliveries"))
+		}
+
+		report := &models.RedispatchReport{Results: make([]*models.RedispatchResult, 0, len(deadLetters))}
+		for _, delivery := range deadLetters {
+			report.Results = append(report.Results, replayDelivery(params.HTTPRequest.Context(), store, dispatcher, delivery, *params.Immediate, logger))
+		}
+		logger.Info("Replayed dead-lettered deliveries", zap.Int("count", len(deadLetters)), zap.Bool("immediate", *params.Immediate))
+		return operations.NewReplayDeadLettersOK().WithPayload(report)
+	}
+}
+
+// newBackfillStatusModel converts a backfill state to its API representation
+func newBackfillStatusModel(state BackfillState, running bool) *models.BackfillStatus {
+	return &models.BackfillStatus{
+		Done:         state.Done,
+		Error:        state.Error,
+		PostsFetched: int64(state.PostsFetched),
+		Running:      running,
+		Since:        strfmt.DateTime(state.Since),
+		UpdatedAt:    strfmt.DateTime(state.UpdatedAt),
+	}
+}
+
+// GetBackfillHandler route returns the progress of the last backfill
+func GetBackfillHandler(store PostStore, backfills *backfillRunner, logger *zap.Logger) operations.GetBackfillHandlerFunc {
+	return func(params operations.GetBackfillParams) middleware.Responder {
+		state, err := store.GetBackfillState(params.HTTPRequest.Context())
+		if errors.Is(err, ErrBackfillNotFound) {
+			return operations.NewGetBackfillNotFound().WithPayload(newErrorModel(err.Error()))
+		}
+		if err != nil {
+			logger.Error("Failed to get backfill state", zap.Error(err))
+			return operations.NewGetBackfillInternalServerError().WithPayload(newErrorModel("failed to get backfill state"))
+		}
+		return operations.NewGetBackfillOK().WithPayload(newBackfillStatusModel(state, backfills.Running()))
+	}
+}
+
+// StartBackfillHandler route starts a backfill of the group feed in the background
+func StartBackfillHandler(store PostStore, backfills *backfillRunner, logger *zap.Logger) operations.StartBackfillHandlerFunc {
+	return func(params operations.StartBackfillParams, _ interface{}) middleware.Responder {
+		since := time.Time(*params.Body.Since)
+		if err := backfills.Start(store, since, logger); err != nil {
+			return operations.NewStartBackfillConflict().WithPayload(newErrorModel(err.Error()))
+		}
+		logger.Info("Started backfill from API", zap.Time("since", since))
+		return operations.NewStartBackfillAccepted().WithPayload(newBackfillStatusModel(BackfillState{Since: since}, true))
+	}
+}
+
+// VerifyFacebookWebhookHandler route answers the subscription verification request of Facebook Webhooks
+func VerifyFacebookWebhookHandler(logger *zap.Logger) operations.VerifyFacebookWebhookHandlerFunc {
+	return func(params operations.VerifyFacebookWebhookParams) middleware.Responder {
+		verifyToken := config.FBWebhookVerifyToken
+		if params.HubMode != "subscribe" || verifyToken == "" ||
+			subtle.ConstantTimeCompare([]byte(params.HubVerifyToken), []byte(verifyToken)) != 1 {
+			logger.Warn("Rejected Facebook webhook subscription", zap.String("mode", params.HubMode))
+			return operations.NewVerifyFacebookWebhookForbidden()
+		}
+		logger.Info("Verified Facebook webhook subscription")
+		return operations.NewVerifyFacebookWebhookOK().WithPayload(params.HubChallenge)
+	}
+}
+
+// ReceiveFacebookWebhookHandler route stores the posts changed according to a Facebook Webhooks notification.
+// Notifications are acknowledged once verified, and processed in the background with the runner, within JOB_TIMEOUT
+// seconds. Notifications that can't be processed are still acknowledged, the scheduled fetch picks the posts up later.
+func ReceiveFacebookWebhookHandler(store PostStore, getSession func(*zap.Logger) (*fb.Session, error), background *backfillRunner, logger *zap.Logger) operations.ReceiveFacebookWebhookHandlerFunc {
+	return func(params operations.ReceiveFacebookWebhookParams) middleware.Responder {
+		// The signature covers the raw body, so the notification isn't declared as a body parameter
+		body, err := ioutil.ReadAll(params.HTTPRequest.Body)
+		if err != nil {
+			return operations.NewReceiveFacebookWebhookBadRequest().WithPayload(newErrorModel("failed to read notification"))
+		}
+		signatureHeader := ""
+		if params.XHubSignature256 != nil {
+			signatureHeader = *params.XHubSignature256
+		}
+		if !verifyHubSignature(config.FBAppSecret, body, signatureHeader) {
+			logger.Warn("Rejected Facebook webhook notification with an invalid signature")
+			return operations.NewReceiveFacebookWebhookForbidden().WithPayload(newErrorModel("invalid signature"))
+		}
+
+		var notification FacebookWebhookNotification
+		if err := json.Unmarshal(body, &notification); err != nil {
+			return operations.NewReceiveFacebookWebhookBadRequest().WithPayload(newErrorModel("malformed notification"))
+		}
+		// Facebook expects an answer within seconds, and the request is cancelled along with the connection
+		started := background.Go(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, time.Duration(config.JobTimeout)*time.Second)
+			defer cancel()
+			fbSession, err := getSession(logger)
+			if err != nil {
+				logger.Error("Unable to create Facebook session", zap.Error(err))
+				return
+			}
+			processFacebookWebhook(ctx, fbSession.WithContext(ctx), store, notification, logger)
+		})
+		if !started {
+			logger.Warn("Shutting down, leaving Facebook webhook notification to the next fetch")
+		}
+		return operations.NewReceiveFacebookWebhookOK()
+	}
+}
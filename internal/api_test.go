@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/swagger/server/models"
+	"go.uber.org/zap"
+)
+
+func newTestAPIServer(t *testing.T, store PostStore) *httptest.Server {
+	api, err := newAPI(context.Background(), store, NewDispatcher(store, zap.NewNop()), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(api.Serve(nil))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postAdmin sends a POST request to an admin endpoint with the admin token
+func postAdmin(t *testing.T, url string, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Admin-Token", config.AdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func getJSON(t *testing.T, url string, out interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response from %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestListPostsHandler(t *testing.T) {
+	store := NewMemoryPostStore()
+	for day := 1; day <= 5; day++ {
+		err := store.UpdateOrInsertPost(context.Background(), PostData{
+			CreatedTime:  time.Date(2020, 10, day, 0, 0, 0, 0, time.UTC),
+			FacebookID:   fmt.Sprintf("1_%d", day),
+			FacebookPost: fb.Result{"message": fmt.Sprintf("day %d", day)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := newTestAPIServer(t, store)
+
+	var seen []string
+	url := server.URL + "/v1/posts?limit=2&created_after=2020-10-02T00:00:00Z"
+	for page := 0; page < 5; page++ {
+		var postList models.PostList
+		if status := getJSON(t, url, &postList); status != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", status)
+		}
+		for _, post := range postList.Posts {
+			seen = append(seen, post.FacebookID)
+		}
+		if postList.NextCursor == "" {
+			break
+		}
+		url = server.URL + "/v1/posts?limit=2&created_after=2020-10-02T00:00:00Z&cursor=" + postList.NextCursor
+	}
+	if fmt.Sprint(seen) != "[1_5 1_4 1_3 1_2]" {
+		t.Errorf("Unexpected posts across pages: %v", seen)
+	}
+
+	if status := getJSON(t, server.URL+"/v1/posts?cursor=garbage", nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid cursor, got %d", status)
+	}
+	if status := getJSON(t, server.URL+"/v1/posts?limit=1000", nil); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an out of range limit, got %d", status)
+	}
+}
+
+func TestGetPostHandler(t *testing.T) {
+	store := NewMemoryPostStore()
+	postData := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"link": "https://youtu.be/dQw4w9WgXcQ"},
+	}
+	queueTestPost(t, store, postData)
+	dispatchRecord := DispatchRecord{DispatchedAt: time.Now().UTC(), Error: "connection refused"}
+	if err := store.RecordDispatch(context.Background(), postData, dispatchRecord); err != nil {
+		t.Fatal(err)
+	}
+	delivery := WebhookDelivery{FacebookID: "1_1", Subscriber: "search", Event: webhookEventNew, Status: webhookDeliveryDelivered}
+	if err := store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestAPIServer(t, store)
+
+	var post models.Post
+	if status := getJSON(t, server.URL+"/v1/posts/1_1", &post); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if post.IsParsed || len(post.DispatchHistory) != 1 || post.DispatchHistory[0].Error != "connection refused" {
+		t.Errorf("Unexpected post: %+v", post)
+	}
+	if len(post.Deliveries) != 2 || post.Deliveries[0].Subscriber != c3poSubscriberName || post.Deliveries[0].Status != webhookDeliveryPending ||
+		post.Deliveries[1].Subscriber != "search" || post.Deliveries[1].Status != webhookDeliveryDelivered {
+		t.Errorf("Expected the delivery status for C-3PO and search, got %+v", post.Deliveries)
+	}
+
+	var apiError models.Error
+	if status := getJSON(t, server.URL+"/v1/posts/missing", &apiError); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing post, got %d", status)
+	}
+}
+
+func TestRedispatchHandlers(t *testing.T) {
+	c3po := newTestC3po(t, true)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = c3po.URL
+		c.Whoami = "test"
+		c.AdminToken = "admin"
+	})
+	store := NewMemoryPostStore()
+	for day := 1; day <= 3; day++ {
+		postData := PostData{
+			CreatedTime:  time.Date(2020, 10, day, 0, 0, 0, 0, time.UTC),
+			FacebookID:   fmt.Sprintf("1_%d", day),
+			FacebookPost: fb.Result{},
+		}
+		if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := newTestAPIServer(t, store)
+
+	resp := postAdmin(t, server.URL+"/v1/posts/1_1/redispatch", "")
+	var result models.RedispatchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if result.Status != models.RedispatchResultStatusQueued {
+		t.Errorf("Expected post to be queued, got %+v", result)
+	}
+	if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryPending {
+		t.Errorf("Expected post to be queued for C-3PO after redispatch, got %+v", delivery)
+	}
+
+	resp = postAdmin(t, server.URL+"/v1/posts/redispatch", `{"created_after": "2020-10-02T00:00:00Z", "immediate": true}`)
+	var report models.RedispatchReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if len(report.Results) != 2 {
+		t.Fatalf("Expected 2 redispatched posts, got %+v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Status != models.RedispatchResultStatusDispatched {
+			t.Errorf("Expected post to be dispatched, got %+v", result)
+		}
+		if delivery := c3poDeliveryOf(t, store, result.FacebookID); delivery.Status != webhookDeliveryDelivered {
+			t.Errorf("Expected the C-3PO delivery to be delivered, got %+v", delivery)
+		}
+	}
+}
+
+func TestBackfillHandlers(t *testing.T) {
+	setTestConfig(t, func(c *Config) { c.AdminToken = "admin" })
+	store := NewMemoryPostStore()
+	release := make(chan struct{})
+	backfills := &backfillRunner{ctx: context.Background(), backfill: func(ctx context.Context, store PostStore, since time.Time, logger *zap.Logger) error {
+		<-release
+		return store.SaveBackfillState(context.Background(), BackfillState{Since: since, PostsFetched: 4, Done: true})
+	}}
+	api, err := newAPIWithBackfill(store, NewDispatcher(store, zap.NewNop()), backfills, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(api.Serve(nil))
+	t.Cleanup(server.Close)
+
+	if status := getJSON(t, server.URL+"/v1/admin/backfill", nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 before any backfill, got %d", status)
+	}
+
+	startBackfill := func() int {
+		resp := postAdmin(t, server.URL+"/v1/admin/backfill", `{"since": "2019-01-01T00:00:00Z"}`)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	resp, err := http.Post(server.URL+"/v1/admin/backfill", "application/json", strings.NewReader(`{"since": "2019-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || backfills.Running() {
+		t.Errorf("Expected a backfill without the admin token to be rejected, got %d", resp.StatusCode)
+	}
+	if status := startBackfill(); status != http.StatusAccepted {
+		t.Errorf("Expected backfill to start, got %d", status)
+	}
+	if status := startBackfill(); status != http.StatusConflict {
+		t.Errorf("Expected conflict while a backfill is running, got %d", status)
+	}
+	close(release)
+
+	for backfills.Running() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	var backfillStatus models.BackfillStatus
+	if status := getJSON(t, server.URL+"/v1/admin/backfill", &backfillStatus); status != http.StatusOK {
+		t.Fatalf("Expected backfill status, got %d", status)
+	}
+	if !backfillStatus.Done || backfillStatus.PostsFetched != 4 || backfillStatus.Running {
+		t.Errorf("Unexpected backfill status %+v", backfillStatus)
+	}
+}
+
+func TestReplayDeadLettersHandler(t *testing.T) {
+	store := NewMemoryPostStore()
+	for day := 1; day <= 2; day++ {
+		postData := PostData{
+			CreatedTime:  time.Date(2020, 10, day, 0, 0, 0, 0, time.UTC),
+			FacebookID:   fmt.Sprintf("1_%d", day),
+			FacebookPost: fb.Result{},
+		}
+		if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, delivery := range []WebhookDelivery{
+		{FacebookID: "1_1", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryFailed, Attempts: 5, LastError: "timeout"},
+		{FacebookID: "1_2", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryDelivered},
+		{FacebookID: "1_2", Subscriber: "search", Event: webhookEventNew, Status: webhookDeliveryFailed, Attempts: 3},
+	} {
+		if err := store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setTestConfig(t, func(c *Config) { c.AdminToken = "admin" })
+	server := newTestAPIServer(t, store)
+
+	var postList models.PostList
+	if status := getJSON(t, server.URL+"/v1/posts?dead_lettered=true", &postList); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(postList.Posts) != 1 || !postList.Posts[0].DeadLettered || postList.Posts[0].DispatchAttempts != 5 {
+		t.Fatalf("Expected only the dead-lettered post, got %+v", postList.Posts)
+	}
+
+	resp := postAdmin(t, server.URL+"/v1/posts/dead-letters/replay", "")
+	var report models.RedispatchReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if len(report.Results) != 2 {
+		t.Fatalf("Expected both dead-lettered deliveries to be replayed, got %+v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Status != models.RedispatchResultStatusQueued {
+			t.Errorf("Expected the dead-lettered delivery to be queued again, got %+v", result)
+		}
+	}
+	deliveries, _ := store.ListWebhookDeliveries(context.Background(), "1_2")
+	for _, delivery := range deliveries {
+		if delivery.Subscriber == "search" && (delivery.Status != webhookDeliveryPending || delivery.Attempts != 0) {
+			t.Errorf("Expected the subscriber delivery to be queued afresh, got %+v", delivery)
+		}
+		if delivery.Subscriber == c3poSubscriberName && delivery.Status != webhookDeliveryDelivered {
+			t.Errorf("Expected the delivered C-3PO delivery to be left alone, got %+v", delivery)
+		}
+	}
+	if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("Expected replayed post to be queued afresh, got %+v", delivery)
+	}
+}
+
+func TestAdminTokenAuth(t *testing.T) {
+	store := NewMemoryPostStore()
+	postData := PostData{CreatedTime: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), FacebookID: "1_1", FacebookPost: fb.Result{}}
+	if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestAPIServer(t, store)
+	redispatch := func(token string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/posts/1_1/redispatch", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("X-Admin-Token", token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Without ADMIN_TOKEN, admin endpoints are disabled
+	if status := redispatch("admin"); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 while ADMIN_TOKEN is unset, got %d", status)
+	}
+
+	setTestConfig(t, func(c *Config) { c.AdminToken = "admin" })
+	if status := redispatch(""); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", status)
+	}
+	if status := redispatch("wrong"); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", status)
+	}
+	if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != "" {
+		t.Errorf("Expected rejected requests to leave the post alone, got %+v", delivery)
+	}
+	if status := redispatch("admin"); status != http.StatusOK {
+		t.Errorf("Expected 200 with the admin token, got %d", status)
+	}
+
+	// Other endpoints stay open
+	if status := getJSON(t, server.URL+"/v1/posts/1_1", nil); status != http.StatusOK {
+		t.Errorf("Expected posts to be readable without a token, got %d", status)
+	}
+}
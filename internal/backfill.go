@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// ErrBackfillRunning is returned when a backfill is started while another one is still running
+var ErrBackfillRunning = errors.New("backfill already running")
+
+// parseBackfillSince accepts either a date or a RFC3339 timestamp
+func parseBackfillSince(value string) (time.Time, error) {
+	if since, err := time.Parse("2006-01-02", value); err == nil {
+		return since, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// nextFeedPage returns the query string of the page after feedResp, without credentials so that it's safe to store.
+// It's empty on the last page.
+func nextFeedPage(feedResp fb.Result) (string, error) {
+	next, _ := feedResp.Get("paging.next").(string)
+	if next == "" {
+		return "", nil
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	query := nextURL.Query()
+	query.Del("access_token")
+	query.Del("appsecret_proof")
+	return query.Encode(), nil
+}
+
+// feedPageParams turns a query string returned by nextFeedPage back into Graph API params
+func feedPageParams(nextPage string) (fb.Params, error) {
+	query, err := url.ParseQuery(nextPage)
+	if err != nil {
+		return nil, err
+	}
+	params := fb.Params{}
+	for key := range query {
+		params[key] = query.Get(key)
+	}
+	return params, nil
+}
+
+// Backfill stores every post of the group feed created since the given time. Graph API calls are cancelled along
+// with ctx, and the backfill resumes from its last saved page when run again.
+func Backfill(ctx context.Context, store PostStore, since time.Time, logger *zap.Logger) error {
+	fbSession, err := getFacebookSession(logger)
+	if err != nil {
+		logger.Error("Unable to create Facebook session", zap.Error(err))
+		return err
+	}
+	logger.Debug("Created Facebook session", zap.Any("fbSession", fbSession))
+
+	ctx, span := startSpan(ctx, "Backfill")
+	err = backfillFeed(ctx, fbSession.WithContext(ctx), store, since, logger)
+	endSpan(span, err)
+	return err
+}
+
+// backfillFeed pages through the whole group feed back to `since`, saving its progress after every page. The feed is
+// sorted by activity rather than creation, so it's read until its last page, leaving out posts older than `since`.
+// An unfinished backfill with the same `since` is resumed from its last saved page.
+func backfillFeed(ctx context.Context, fbSession *fb.Session, store PostStore, since time.Time, logger *zap.Logger) error {
+	state, err := store.GetBackfillState(ctx)
+	if err != nil && !errors.Is(err, ErrBackfillNotFound) {
+		return err
+	}
+
+	params := fb.Params{"since": since.Unix()}
+	for key, value := range fbFeedParams {
+		params[key] = value
+	}
+	if err == nil && state.Since.Equal(since) && !state.Done && state.NextPage != "" {
+		logger.Info("Resuming backfill", zap.Time("since", since), zap.Int("postsFetched", state.PostsFetched))
+		params, err = feedPageParams(state.NextPage)
+		if err != nil {
+			return err
+		}
+	} else {
+		logger.Info("Starting backfill", zap.Time("since", since))
+		state = BackfillState{Since: since}
+	}
+	state.Error = ""
+
+	// Configure exponential backoff for retries
+	exponentialBackoff := graphBackOff(fbSession)
+
+	for {
+		var feedResp fb.Result
+		_, pageSpan := startSpan(ctx, "feed page")
+		err := backoff.RetryNotify(func() error {
+			var fbError error
+			feedResp, fbError = fbSession.Get(fmt.Sprintf("%s/feed", fbGroupID), params)
+			countGraphError(fbError)
+			return fbError
+		}, exponentialBackoff, retryNotifyFunc)
+		endSpan(pageSpan, err)
+		if err != nil {
+			logger.Error("Failed fetching feed page for backfill", zap.Error(err))
+			return saveBackfillError(ctx, store, state, err)
+		}
+		var posts []fb.Result
+		if err := feedResp.DecodeField("data", &posts); err != nil {
+			logger.Error("Failed decoding feed page for backfill", zap.Error(err))
+			return saveBackfillError(ctx, store, state, err)
+		}
+
+		for _, post := range posts {
+			// Resuming starts over from this page
+			if err := ctx.Err(); err != nil {
+				return saveBackfillError(ctx, store, state, err)
+			}
+			postsFetched.WithLabelValues("backfill").Inc()
+			postData, err := decodeFeedPost(post, logger)
+			if err != nil {
+				continue
+			}
+			if postData.CreatedTime.Before(since) {
+				continue
+			}
+
+			err = storeFeedPost(ctx, fbSession, store, postData, logger)
+			if err != nil {
+				logger.Warn("Failed to store Facebook post", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+				continue
+			}
+			state.PostsFetched++
+		}
+
+		nextPage, err := nextFeedPage(feedResp)
+		if err != nil {
+			logger.Error("Failed reading next feed page for backfill", zap.Error(err))
+			return saveBackfillError(ctx, store, state, err)
+		}
+		state.Done = nextPage == ""
+		state.NextPage = nextPage
+		state.UpdatedAt = time.Now()
+		if err := store.SaveBackfillState(ctx, state); err != nil {
+			return err
+		}
+		if state.Done {
+			logger.Info("Backfill finished", zap.Time("since", since), zap.Int("postsFetched", state.PostsFetched))
+			return nil
+		}
+
+		params, err = feedPageParams(nextPage)
+		if err != nil {
+			return saveBackfillError(ctx, store, state, err)
+		}
+	}
+}
+
+// saveBackfillError records why a backfill stopped, keeping its last page so that it can be resumed. It is
+// recorded even if the backfill was cancelled.
+func saveBackfillError(ctx context.Context, store PostStore, state BackfillState, backfillErr error) error {
+	ctx, cancel := detachContext(ctx)
+	defer cancel()
+	state.Error = backfillErr.Error()
+	state.UpdatedAt = time.Now()
+	if err := store.SaveBackfillState(ctx, state); err != nil {
+		return err
+	}
+	return backfillErr
+}
+
+// backfillRunner runs backfills in the background, one at a time, along with other work started by the API that
+// outlives its request
+type backfillRunner struct {
+	// ctx cancels the running backfill and background work when done
+	ctx     context.Context
+	mu      sync.Mutex
+	running bool
+	wg      sync.WaitGroup
+	// backfill is the job run by Start, Backfill outside of tests
+	backfill func(ctx context.Context, store PostStore, since time.Time, logger *zap.Logger) error
+}
+
+// Start runs a backfill in a goroutine, or returns ErrBackfillRunning if one is already running
+func (r *backfillRunner) Start(store PostStore, since time.Time, logger *zap.Logger) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return ErrBackfillRunning
+	}
+	r.running = true
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			r.running = false
+			r.mu.Unlock()
+		}()
+		if err := r.backfill(r.ctx, store, since, logger); err != nil {
+			logger.Error("Backfill failed", zap.Time("since", since), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Go runs f in the background with the context of the runner, unless it's already cancelled. It reports whether f
+// was started.
+func (r *backfillRunner) Go(f func(ctx context.Context)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx.Err() != nil {
+		return false
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		f(r.ctx)
+	}()
+	return true
+}
+
+// Wait waits up to timeout for the running backfill and background work to return, once the context of the runner
+// is cancelled. It reports whether they returned in time.
+func (r *backfillRunner) Wait(timeout time.Duration) bool {
+	// Work started before the context was cancelled has been added to the wait group once the lock is free
+	r.mu.Lock()
+	r.mu.Unlock()
+	stopped := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Running reports whether a backfill started by the runner is still in progress
+func (r *backfillRunner) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
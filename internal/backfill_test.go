@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// newTestFeed serves the group feed as pages of created_time values, linked with `after` cursors, and
+// a comment with a reply on every post. It returns the Graph API session and a log of the requested feed cursors.
+func newTestFeed(t *testing.T, pages [][]string) (*fb.Session, *[]string) {
+	var requestedPages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/comments") {
+			pathParts := strings.Split(r.URL.Path, "/")
+			postID := pathParts[len(pathParts)-2]
+			_, _ = fmt.Fprintf(w, `{"data": [
+				{"id": "%[1]s_c1", "created_time": "2020-10-01T00:00:00+00:00", "message": "song"},
+				{"id": "%[1]s_c2", "created_time": "2020-10-02T00:00:00+00:00", "parent": {"id": "%[1]s_c1"}}
+			]}`, postID)
+			return
+		}
+		after := r.URL.Query().Get("after")
+		requestedPages = append(requestedPages, after)
+		pageIndex := 0
+		if after != "" {
+			_, _ = fmt.Sscanf(after, "p%d", &pageIndex)
+		}
+
+		var posts []map[string]interface{}
+		for i, createdTime := range pages[pageIndex] {
+			posts = append(posts, map[string]interface{}{
+				"id":           fmt.Sprintf("%s_%d_%d", fbGroupID, pageIndex, i),
+				"created_time": createdTime,
+			})
+		}
+		response := map[string]interface{}{"data": posts}
+		if pageIndex+1 < len(pages) {
+			response["paging"] = map[string]interface{}{
+				"next": fmt.Sprintf("http://%s%s?access_token=secret&limit=100&after=p%d", r.Host, r.URL.Path, pageIndex+1),
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	fbSession := fb.New("app", "secret").Session("token")
+	fbSession.BaseURL = server.URL + "/"
+	fbSession.Version = "v8.0"
+	return fbSession, &requestedPages
+}
+
+func TestBackfillFeed(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fbSession, requestedPages := newTestFeed(t, [][]string{
+		{"2020-10-01T00:00:00+00:00", "2020-09-01T00:00:00+00:00"},
+		{"2019-06-01T00:00:00+00:00", "2020-02-01T00:00:00+00:00"},
+		{"2019-05-01T00:00:00+00:00"},
+		{"2019-04-01T00:00:00+00:00", "2020-03-01T00:00:00+00:00"},
+	})
+	store := NewMemoryPostStore()
+
+	if err := backfillFeed(context.Background(), fbSession, store, since, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(*requestedPages, ",") != ",p1,p2,p3" {
+		t.Errorf("Expected the backfill to read the feed until its last page, requested %v", *requestedPages)
+	}
+	state, err := store.GetBackfillState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Done || state.PostsFetched != 4 || state.NextPage != "" || !state.Since.Equal(since) {
+		t.Errorf("Unexpected backfill state %+v", state)
+	}
+	if _, err := store.GetPost(context.Background(), fbGroupID+"_3_1"); err != nil {
+		t.Errorf("Expected a recent post listed after a page of older ones to be stored, got %v", err)
+	}
+	if _, err := store.GetPost(context.Background(), fbGroupID+"_1_0"); err != ErrPostNotFound {
+		t.Errorf("Expected posts older than since to be skipped, got %v", err)
+	}
+
+	postID := fbGroupID + "_0_0"
+	comments, err := store.ListComments(context.Background(), postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[0].FacebookID != postID+"_c1" || comments[1].ParentID != postID+"_c1" {
+		t.Errorf("Expected the comment thread of %s to be stored, got %+v", postID, comments)
+	}
+}
+
+func TestBackfillFeedResumes(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fbSession, requestedPages := newTestFeed(t, [][]string{
+		{"2020-10-01T00:00:00+00:00"},
+		{"2020-09-01T00:00:00+00:00"},
+		{"2020-08-01T00:00:00+00:00"},
+	})
+	store := NewMemoryPostStore()
+	_ = store.SaveBackfillState(context.Background(), BackfillState{
+		Since:        since,
+		NextPage:     "after=p1&limit=100",
+		PostsFetched: 1,
+		Error:        "connection reset",
+	})
+
+	if err := backfillFeed(context.Background(), fbSession, store, since, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(*requestedPages, ",") != "p1,p2" {
+		t.Errorf("Expected the backfill to resume from p1, requested %v", *requestedPages)
+	}
+	state, _ := store.GetBackfillState(context.Background())
+	if !state.Done || state.PostsFetched != 3 || state.Error != "" {
+		t.Errorf("Unexpected backfill state %+v", state)
+	}
+}
+
+func TestNextFeedPage(t *testing.T) {
+	nextPage, err := nextFeedPage(fb.Result{"paging": map[string]interface{}{
+		"next": "https://graph.facebook.com/v8.0/1/feed?access_token=secret&appsecret_proof=proof&after=abc",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextPage != "after=abc" {
+		t.Errorf("Expected credentials to be stripped from the next page, got %q", nextPage)
+	}
+
+	nextPage, _ = nextFeedPage(fb.Result{"data": []interface{}{}})
+	if nextPage != "" {
+		t.Errorf("Expected no next page, got %q", nextPage)
+	}
+}
+
+func TestBackfillRunnerWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backfills := &backfillRunner{ctx: ctx, backfill: func(ctx context.Context, store PostStore, since time.Time, logger *zap.Logger) error {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		return ctx.Err()
+	}}
+	if !backfills.Wait(time.Millisecond) {
+		t.Error("Expected no backfill to wait for")
+	}
+	if err := backfills.Start(NewMemoryPostStore(), time.Now(), zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	backfills.Go(func(ctx context.Context) { <-ctx.Done() })
+	if backfills.Wait(10 * time.Millisecond) {
+		t.Error("Expected the running backfill and background work to be waited for until they're cancelled")
+	}
+
+	cancel()
+	if !backfills.Wait(time.Second) {
+		t.Error("Expected the cancelled backfill to return in time")
+	}
+	if backfills.Running() {
+		t.Error("Expected the backfill to be done")
+	}
+	if backfills.Go(func(context.Context) {}) {
+		t.Error("Expected no background work to start once the runner is cancelled")
+	}
+}
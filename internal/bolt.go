@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Bucket holding posts keyed by Facebook ID
+var boltPostsBucket = []byte("feed")
+
+// Bucket mirroring the former DynamoDB parsed_index GSI, keyed by created_time and Facebook ID. It's moved over to
+// C-3PO deliveries when the store is opened.
+var boltParsedIndexBucket = []byte("parsed_index")
+
+// Bucket holding comments, keyed by post and comment Facebook IDs
+var boltCommentsBucket = []byte("comments")
+
+// Bucket holding engagement snapshots, keyed by Facebook ID and sampling time
+var boltEngagementBucket = []byte("engagement_snapshots")
+
+// Bucket holding the progress of background jobs, keyed by job name
+var boltJobStateBucket = []byte("job_state")
+
+// Bucket holding webhook deliveries, keyed by Facebook ID and subscriber
+var boltWebhookDeliveriesBucket = []byte("webhook_deliveries")
+
+// Key of the backfill progress in the job state bucket
+var boltBackfillKey = []byte("backfill")
+
+// BoltPostStore is a PostStore backed by a single BoltDB file
+type BoltPostStore struct {
+	db     *bolt.DB
+	logger *zap.Logger
+}
+
+// InitializeBoltStore opens (or creates) the BoltDB file and its buckets
+func InitializeBoltStore(path string, logger *zap.Logger) (*BoltPostStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltPostsBucket, boltCommentsBucket, boltEngagementBucket, boltJobStateBucket, boltWebhookDeliveriesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return migrateBoltParsedIndex(tx, logger)
+	})
+	if err != nil {
+		logger.Error("Failed creating BoltDB buckets", zap.Error(err))
+		_ = db.Close()
+		return nil, err
+	}
+	return &BoltPostStore{db: db, logger: logger}, nil
+}
+
+// Close releases the BoltDB file lock
+func (s *BoltPostStore) Close() error {
+	return s.db.Close()
+}
+
+// migrateBoltParsedIndex queues the posts of the former parsed index for C-3PO as deliveries, strips the dispatch
+// state from them and drops the index
+func migrateBoltParsedIndex(tx *bolt.Tx, logger *zap.Logger) error {
+	parsedIndex := tx.Bucket(boltParsedIndexBucket)
+	if parsedIndex == nil {
+		return nil
+	}
+	migrated := 0
+	err := parsedIndex.ForEach(func(_, facebookID []byte) error {
+		value := tx.Bucket(boltPostsBucket).Get(facebookID)
+		if value == nil {
+			return nil
+		}
+		var legacyState legacyDispatchState
+		if err := json.Unmarshal(value, &legacyState); err != nil {
+			return err
+		}
+		if err := putBoltWebhookDelivery(tx, legacyState.c3poDelivery()); err != nil {
+			return err
+		}
+		postData, err := getBoltPost(tx, string(facebookID))
+		if err != nil {
+			return err
+		}
+		migrated++
+		return putBoltPost(tx, postData)
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("Moved queued posts over to C-3PO deliveries", zap.Int("count", migrated))
+	return tx.DeleteBucket(boltParsedIndexBucket)
+}
+
+func getBoltPost(tx *bolt.Tx, facebookID string) (PostData, error) {
+	var postData PostData
+	value := tx.Bucket(boltPostsBucket).Get([]byte(facebookID))
+	if value == nil {
+		return postData, ErrPostNotFound
+	}
+	err := json.Unmarshal(value, &postData)
+	return postData, err
+}
+
+func putBoltPost(tx *bolt.Tx, postData PostData) error {
+	value, err := json.Marshal(postData)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(boltPostsBucket).Put([]byte(postData.FacebookID), value)
+}
+
+// UpdateOrInsertPost creates a post, or overwrites it if it was edited since it was stored.
+func (s *BoltPostStore) UpdateOrInsertPost(_ context.Context, postData PostData) error {
+	unchanged := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getBoltPost(tx, postData.FacebookID)
+		if err == nil {
+			if !isPostChanged(existing, postData) {
+				unchanged = true
+				return nil
+			}
+			postData = withStoredFields(postData, existing)
+		} else if err != ErrPostNotFound {
+			return err
+		}
+		return putBoltPost(tx, postData)
+	})
+	if err != nil {
+		s.logger.Error("Failed to UpdateOrInsertPost", zap.String("FacebookId", postData.FacebookID), zap.Error(err))
+		return err
+	}
+	if unchanged {
+		s.logger.Debug("Post unchanged, skipping update", zap.String("FacebookID", postData.FacebookID))
+		return nil
+	}
+	s.logger.Info("UpdateOrInsertPost success", zap.String("FacebookID", postData.FacebookID))
+	return nil
+}
+
+// MarkPostAsDeleted tombstones a post
+func (s *BoltPostStore) MarkPostAsDeleted(_ context.Context, postData PostData) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getBoltPost(tx, postData.FacebookID)
+		if err != nil {
+			return err
+		}
+		existing.DeletedTime = postData.DeletedTime
+		return putBoltPost(tx, existing)
+	})
+	if err != nil {
+		s.logger.Warn("MarkPostAsDeleted failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RecordDispatch appends a dispatch attempt to the post's history, dropping the oldest beyond maxDispatchHistory
+func (s *BoltPostStore) RecordDispatch(_ context.Context, postData PostData, record DispatchRecord) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getBoltPost(tx, postData.FacebookID)
+		if err != nil {
+			return err
+		}
+		existing.DispatchHistory = appendDispatchRecord(existing.DispatchHistory, record)
+		return putBoltPost(tx, existing)
+	})
+	if err != nil {
+		s.logger.Warn("RecordDispatch failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetPost fetches a post by its Facebook ID
+func (s *BoltPostStore) GetPost(_ context.Context, facebookID string) (PostData, error) {
+	var postData PostData
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		postData, err = getBoltPost(tx, facebookID)
+		return err
+	})
+	return postData, err
+}
+
+// ListPosts returns a page of posts matching the query, newest first
+func (s *BoltPostStore) ListPosts(_ context.Context, query PostQuery) (PostPage, error) {
+	var posts []PostData
+	c3poStatuses := map[string]string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		deliveriesBucket := tx.Bucket(boltWebhookDeliveriesBucket)
+		return tx.Bucket(boltPostsBucket).ForEach(func(facebookID, value []byte) error {
+			var postData PostData
+			if err := json.Unmarshal(value, &postData); err != nil {
+				s.logger.Error("Failed to unmarshal post from DB", zap.ByteString("facebookID", facebookID), zap.Error(err))
+				return nil
+			}
+			posts = append(posts, postData)
+
+			var delivery WebhookDelivery
+			if deliveryValue := deliveriesBucket.Get(boltWebhookDeliveryKey(postData.FacebookID, c3poSubscriberName)); deliveryValue != nil {
+				if err := json.Unmarshal(deliveryValue, &delivery); err != nil {
+					return err
+				}
+				c3poStatuses[postData.FacebookID] = delivery.Status
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return PostPage{}, err
+	}
+
+	return paginatePosts(posts, c3poStatuses, query)
+}
+
+// UpdateOrInsertComment stores a comment of a post, overwriting any previous version
+func (s *BoltPostStore) UpdateOrInsertComment(_ context.Context, comment CommentData) error {
+	value, err := json.Marshal(comment)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCommentsBucket).Put([]byte(comment.PostID+"#"+comment.FacebookID), value)
+	})
+	if err != nil {
+		s.logger.Warn("UpdateOrInsertComment failed", zap.String("FacebookID", comment.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteComment removes a stored comment of a post, if any
+func (s *BoltPostStore) DeleteComment(_ context.Context, postID string, commentID string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCommentsBucket).Delete([]byte(postID + "#" + commentID))
+	})
+	if err != nil {
+		s.logger.Warn("DeleteComment failed", zap.String("FacebookID", commentID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListComments returns the comments of a post, replies included, oldest first
+func (s *BoltPostStore) ListComments(_ context.Context, postID string) ([]CommentData, error) {
+	comments := []CommentData{}
+	prefix := []byte(postID + "#")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltCommentsBucket).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			var comment CommentData
+			if err := json.Unmarshal(value, &comment); err != nil {
+				return err
+			}
+			comments = append(comments, comment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortComments(comments)
+	return comments, nil
+}
+
+// boltEngagementKey sorts the snapshots of a post together, oldest first
+func boltEngagementKey(snapshot EngagementSnapshot) []byte {
+	return []byte(snapshot.FacebookID + "#" + snapshot.SampledAt.UTC().Format(time.RFC3339Nano))
+}
+
+// RecordEngagementSnapshot stores a sample of the reaction and comment totals of a post
+func (s *BoltPostStore) RecordEngagementSnapshot(_ context.Context, snapshot EngagementSnapshot) error {
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltEngagementBucket).Put(boltEngagementKey(snapshot), value)
+	})
+	if err != nil {
+		s.logger.Warn("RecordEngagementSnapshot failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListEngagementSnapshots returns the engagement samples of a post, oldest first
+func (s *BoltPostStore) ListEngagementSnapshots(_ context.Context, facebookID string) ([]EngagementSnapshot, error) {
+	snapshots := []EngagementSnapshot{}
+	prefix := []byte(facebookID + "#")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltEngagementBucket).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			var snapshot EngagementSnapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+		return nil
+	})
+	return snapshots, err
+}
+
+// GetBackfillState returns the progress of the last backfill
+func (s *BoltPostStore) GetBackfillState(_ context.Context) (BackfillState, error) {
+	var state BackfillState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltJobStateBucket).Get(boltBackfillKey)
+		if value == nil {
+			return ErrBackfillNotFound
+		}
+		return json.Unmarshal(value, &state)
+	})
+	return state, err
+}
+
+// SaveBackfillState persists the progress of the running backfill
+func (s *BoltPostStore) SaveBackfillState(_ context.Context, state BackfillState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltJobStateBucket).Put(boltBackfillKey, value)
+	})
+	if err != nil {
+		s.logger.Warn("SaveBackfillState failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// boltWebhookDeliveryKey sorts the deliveries of a post together, by subscriber
+func boltWebhookDeliveryKey(facebookID string, subscriber string) []byte {
+	return []byte(facebookID + "#" + subscriber)
+}
+
+func putBoltWebhookDelivery(tx *bolt.Tx, delivery WebhookDelivery) error {
+	value, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(boltWebhookDeliveriesBucket).Put(boltWebhookDeliveryKey(delivery.FacebookID, delivery.Subscriber), value)
+}
+
+// UpdateOrInsertWebhookDelivery stores the delivery state of a post for a subscriber
+func (s *BoltPostStore) UpdateOrInsertWebhookDelivery(_ context.Context, delivery WebhookDelivery) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return putBoltWebhookDelivery(tx, delivery)
+	})
+	if err != nil {
+		s.logger.Warn("UpdateOrInsertWebhookDelivery failed", zap.String("FacebookID", delivery.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateWebhookDelivery stores the delivery state of a post for a subscriber, unless it changed since it was read
+func (s *BoltPostStore) UpdateWebhookDelivery(_ context.Context, delivery WebhookDelivery, readUpdatedAt time.Time) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(boltWebhookDeliveriesBucket).Get(boltWebhookDeliveryKey(delivery.FacebookID, delivery.Subscriber)); value != nil {
+			var stored WebhookDelivery
+			if err := json.Unmarshal(value, &stored); err != nil {
+				return err
+			}
+			if !stored.UpdatedAt.Equal(readUpdatedAt) {
+				return ErrWebhookDeliveryChanged
+			}
+		}
+		return putBoltWebhookDelivery(tx, delivery)
+	})
+	if err != nil && err != ErrWebhookDeliveryChanged {
+		s.logger.Warn("UpdateWebhookDelivery failed", zap.String("FacebookID", delivery.FacebookID), zap.Error(err))
+	}
+	return err
+}
+
+// ListWebhookDeliveries returns the delivery state of a post for every subscriber
+func (s *BoltPostStore) ListWebhookDeliveries(_ context.Context, facebookID string) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	prefix := []byte(facebookID + "#")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltWebhookDeliveriesBucket).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			var delivery WebhookDelivery
+			if err := json.Unmarshal(value, &delivery); err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// QueryWebhookDeliveries scans the deliveries bucket and calls fn with the ones in the given status, oldest first
+func (s *BoltPostStore) QueryWebhookDeliveries(_ context.Context, status string, fn func(delivery WebhookDelivery) bool) error {
+	// Collect first so that fn is free to write to the store
+	var matchingDeliveries []WebhookDelivery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltWebhookDeliveriesBucket).ForEach(func(key, value []byte) error {
+			var delivery WebhookDelivery
+			if err := json.Unmarshal(value, &delivery); err != nil {
+				s.logger.Error("Failed to unmarshal webhook delivery from DB", zap.ByteString("key", key), zap.Error(err))
+				return nil
+			}
+			if delivery.Status == status {
+				matchingDeliveries = append(matchingDeliveries, delivery)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	sortWebhookDeliveriesByAge(matchingDeliveries)
+	for _, delivery := range matchingDeliveries {
+		if !fn(delivery) {
+			break
+		}
+	}
+	return nil
+}
@@ -2,120 +2,259 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/signature"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-// dispatchItem parses and sends a post to C-3PO and marks the post as read if successful
-func dispatchItem(dynamoSession *dynamodb.DynamoDB, entry map[string]*dynamodb.AttributeValue, logger *zap.Logger) error {
-	// Parse entry
-	var postData PostData
-	err := unmarshalMapWithEmptyCollections(entry, &postData)
-	if err != nil {
-		logger.Error("Failed to unmarshal post from DB", zap.Any("entry", entry))
-		return err
-	}
+// errC3poParseFailed is recorded when C-3PO responds but doesn't accept the post
+var errC3poParseFailed = errors.New("C-3PO failed to parse the post")
+
+// errC3poEndpointUnsupported is returned when C-3PO doesn't serve the requested endpoint
+var errC3poEndpointUnsupported = errors.New("C-3PO doesn't support this endpoint")
+
+// errC3poNoBatchResult is recorded for posts of a batch that C-3PO's response doesn't mention
+var errC3poNoBatchResult = errors.New("C-3PO didn't return a result for the post")
+
+// errC3poDeleteFailed is returned when C-3PO responds but doesn't accept a deletion
+var errC3poDeleteFailed = errors.New("C-3PO failed to delete the post")
+
+// sendToC3po POSTs a JSON payload to a C-3PO endpoint and returns its response. The request is cancelled along
+// with ctx or after REQUEST_TIMEOUT seconds, and the trace context of ctx is propagated to C-3PO in the request
+// headers.
+func sendToC3po(ctx context.Context, path string, payload interface{}, logger *zap.Logger) (c3poResponse C3poResponse, err error) {
+	url := fmt.Sprintf("%s%s", config.C3POURI, path)
+	ctx, span := startSpan(ctx, "POST "+path, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethodKey.String("POST"), semconv.HTTPURLKey.String(url)))
+	defer func() { endSpan(span, err) }()
 
 	// Prepare request
-	requestBody, err := json.Marshal(C3poRequest{FacebookPost: postData.FacebookPost})
+	requestBody, err := json.Marshal(payload)
 	if err != nil {
-		logger.Error("Failed to marshal DB post to Facebook post", zap.Object("postData", postData), zap.Error(err))
-		return err
+		logger.Error("Failed to marshal C-3PO request", zap.String("path", path), zap.Error(err))
+		return c3poResponse, err
 	}
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf("%s/v1/data/post", GetEnv("C3PO_URI", "")),
-		bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
-		logger.Error("Failed to generate post request payload for C-3PO", zap.Object("postData", postData), zap.Error(err))
-		return err
+		logger.Error("Failed to generate request payload for C-3PO", zap.String("path", path), zap.Error(err))
+		return c3poResponse, err
+	}
+	if config.Whoami != "" {
+		req.Header.Set("whoami", config.Whoami)
+	}
+	if config.C3POSigningSecret != "" {
+		signature.SignRequest(req, []byte(config.C3POSigningSecret), requestBody, time.Now())
 	}
-	req.Header.Set("whoami", whoamiHeaderVal)
 	req.Header.Set("Content-Type", "application/json")
+	injectTraceContext(ctx, req.Header)
 
 	// Make POST request to C3PO
-	client := &http.Client{}
+	client := newHTTPClient()
+	requestStart := time.Now()
 	resp, err := client.Do(req)
+	c3poRequestDuration.WithLabelValues(path).Observe(time.Since(requestStart).Seconds())
 	if err != nil {
-		logger.Warn("POST request to C-3PO failed", zap.Error(err))
-		return err
+		logger.Warn("POST request to C-3PO failed", zap.String("path", path), zap.Error(err))
+		return c3poResponse, err
 	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
 
 	// Parse response body as bytes
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		logger.Warn("Failed parsing C-3PO response as bytes", zap.Error(err))
-		return err
+		return c3poResponse, err
+	}
+	err = resp.Body.Close()
+	if err != nil {
+		logger.Warn("Failed to closed HTTP response body", zap.Error(err))
+		return c3poResponse, err
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return c3poResponse, errC3poEndpointUnsupported
 	}
 
 	// Parse response
-	var c3poResponse C3poResponse
 	err = json.Unmarshal(body, &c3poResponse)
 	if err != nil {
 		logger.Error("Failed to unmarshal JSON response from C-3PO", zap.Error(err))
-		return err
+		return c3poResponse, err
 	}
-	if c3poResponse.Success {
-		markParsedSuccess := MarkPostAsParsed(dynamoSession, postData, logger)
-		if markParsedSuccess {
-			logger.Info("Successfully parsed", zap.String("postId", postData.FacebookID))
-		} else {
-			logger.Warn("Failed to parse", zap.String("postId", postData.FacebookID))
-		}
-	} else {
-		logger.Warn("Failed to parse", zap.String("postId", postData.FacebookID))
+	return c3poResponse, nil
+}
+
+// newC3poRequest builds the C-3PO representation of a post, its comments and its music links
+func newC3poRequest(postData PostData, comments []CommentData) C3poRequest {
+	c3poRequest := C3poRequest{
+		FacebookPost: postData.FacebookPost,
+		Comments:     make([]fb.Result, 0, len(comments)),
+		Links:        postData.Links,
 	}
+	// Posts stored before links were extracted don't have them yet
+	if c3poRequest.Links == nil {
+		c3poRequest.Links = extractMusicLinks(postData, comments)
+	}
+	for _, comment := range comments {
+		c3poRequest.Comments = append(c3poRequest.Comments, comment.FacebookComment)
+	}
+	return c3poRequest
+}
 
-	err = resp.Body.Close()
+// postToC3po sends a post to C-3PO along with its comments, and returns C-3PO's response
+func postToC3po(ctx context.Context, postData PostData, comments []CommentData, logger *zap.Logger) (C3poResponse, error) {
+	return sendToC3po(ctx, "/v1/data/post", newC3poRequest(postData, comments), logger)
+}
+
+// deletePostFromC3po tells C-3PO that a post was deleted from the group
+func deletePostFromC3po(ctx context.Context, postData PostData, logger *zap.Logger) error {
+	c3poResponse, err := sendToC3po(ctx, "/v1/data/post/delete", C3poDeleteRequest{
+		FacebookID:  postData.FacebookID,
+		DeletedTime: *postData.DeletedTime,
+	}, logger)
 	if err != nil {
-		logger.Warn("Failed to closed HTTP response body", zap.Error(err))
 		return err
 	}
+	if !c3poResponse.Success {
+		return errC3poDeleteFailed
+	}
 	return nil
 }
 
-// DispatchFreshPosts picks up the posts which have is_parsed=false and sends them to C3PO
-func DispatchFreshPosts(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
-	if whoamiHeaderVal == "" {
-		logger.Fatal("C-3PO header env variable `WHOAMI` not present")
+// dispatchItem sends the event of a pending C-3PO delivery: the post along with its comments, or its deletion.
+// Failed attempts, including a post that can't be read from the store, are retried with backoff by the dispatcher.
+func dispatchItem(ctx context.Context, store PostStore, delivery WebhookDelivery, logger *zap.Logger) error {
+	ctx, span := startSpan(ctx, "dispatchItem", trace.WithAttributes(label.String("facebook_id", delivery.FacebookID),
+		label.String("event", delivery.Event)))
+	defer span.End()
+
+	postData, err := store.GetPost(ctx, delivery.FacebookID)
+	if err != nil {
+		logger.Warn("Failed to read post", zap.String("postId", delivery.FacebookID), zap.Error(err))
+		return finishDelivery(ctx, store, c3poSubscriber(), delivery, err, logger)
+	}
+	if delivery.Event == webhookEventDeleted {
+		err = deletePostFromC3po(ctx, postData, logger)
+		return finishDispatch(ctx, store, delivery, postData, err, logger)
 	}
 
-	// Fetch all posts which are not yet parsed
-	fetchUnparsedPostsQuery := dynamodb.QueryInput{
-		ExpressionAttributeNames:  map[string]*string{"#isParsed": &parsedGsiPartitionKey},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":isParsed": {S: aws.String("false")}},
-		IndexName:                 aws.String(parsedGsiIndexName),
-		KeyConditionExpression:    aws.String("#isParsed = :isParsed"),
-		ScanIndexForward:          aws.Bool(false),
-		TableName:                 aws.String(tableName),
+	comments, err := store.ListComments(ctx, postData.FacebookID)
+	if err != nil {
+		logger.Warn("Failed to read comments of post", zap.String("postId", postData.FacebookID), zap.Error(err))
+		return finishDelivery(ctx, store, c3poSubscriber(), delivery, err, logger)
 	}
+	c3poResponse, err := postToC3po(ctx, postData, comments, logger)
+	if err == nil && !c3poResponse.Success {
+		logger.Warn("Failed to parse", zap.String("postId", postData.FacebookID))
+		err = errC3poParseFailed
+	}
+	return finishDispatch(ctx, store, delivery, postData, err, logger)
+}
 
-	err := dynamoSession.QueryPages(&fetchUnparsedPostsQuery, func(output *dynamodb.QueryOutput, b bool) bool {
-		for _, entry := range output.Items {
-			err := dispatchItem(dynamoSession, entry, logger)
-			if err != nil {
-				logger.Warn("Dispatching post to C-3PO failed", zap.Error(err))
-				continue
-			}
-		}
+// finishDispatch records an attempt at a C-3PO delivery in the dispatch history of the post, and stores its outcome
+// on the delivery. Attempts cut short by the end of ctx leave the delivery pending as it was, since C-3PO isn't at
+// fault. The outcome is recorded even if ctx is done by then, so that posts C-3PO accepted aren't sent again.
+func finishDispatch(ctx context.Context, store PostStore, delivery WebhookDelivery, postData PostData, err error, logger *zap.Logger) error {
+	if err != nil && ctx.Err() != nil {
+		logger.Info("Dispatch cancelled, leaving post queued", zap.String("postId", postData.FacebookID), zap.Error(err))
+		return err
+	}
+	ctx, cancel := detachContext(ctx)
+	defer cancel()
 
-		if len(output.LastEvaluatedKey) != 0 {
-			fetchUnparsedPostsQuery.SetExclusiveStartKey(output.LastEvaluatedKey)
-			return true
+	// Keep track of the attempt, regardless of the outcome
+	countDispatch(err)
+	dispatchRecord := DispatchRecord{DispatchedAt: time.Now().UTC(), Success: err == nil}
+	if err != nil {
+		dispatchRecord.Error = err.Error()
+	}
+	if recordErr := store.RecordDispatch(ctx, postData, dispatchRecord); recordErr != nil {
+		logger.Warn("Failed to record dispatch attempt", zap.String("postId", postData.FacebookID), zap.Error(recordErr))
+	}
+	if err == nil {
+		logger.Info("Successfully dispatched", zap.String("postId", postData.FacebookID), zap.String("event", delivery.Event))
+	}
+	return finishDelivery(ctx, store, c3poSubscriber(), delivery, err, logger)
+}
+
+// dispatchBatch sends the posts of several pending C-3PO deliveries to the batch endpoint in a single request, and
+// records the outcome of every delivery. Deletions aren't batched. Posts that can't be read from the store are left
+// out of the batch, and their deliveries recorded as failed attempts. It returns errC3poEndpointUnsupported without
+// recording anything if C-3PO has no batch endpoint.
+func dispatchBatch(ctx context.Context, store PostStore, deliveries []WebhookDelivery, logger *zap.Logger) error {
+	ctx, span := startSpan(ctx, "dispatchBatch", trace.WithAttributes(label.Int("size", len(deliveries))))
+	defer span.End()
+
+	posts := make([]PostData, 0, len(deliveries))
+	sent := make([]WebhookDelivery, 0, len(deliveries))
+	var unreadable []WebhookDelivery
+	var readErrors []error
+	c3poBatchRequest := C3poBatchRequest{Posts: make([]C3poRequest, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		postData, err := store.GetPost(ctx, delivery.FacebookID)
+		if err != nil {
+			logger.Warn("Failed to read post", zap.String("postId", delivery.FacebookID), zap.Error(err))
+			unreadable, readErrors = append(unreadable, delivery), append(readErrors, err)
+			continue
 		}
-		return false
-	})
+		comments, err := store.ListComments(ctx, postData.FacebookID)
+		if err != nil {
+			logger.Warn("Failed to read comments of post", zap.String("postId", postData.FacebookID), zap.Error(err))
+			unreadable, readErrors = append(unreadable, delivery), append(readErrors, err)
+			continue
+		}
+		c3poRequest := newC3poRequest(postData, comments)
+		c3poRequest.FacebookID = postData.FacebookID
+		c3poBatchRequest.Posts = append(c3poBatchRequest.Posts, c3poRequest)
+		posts = append(posts, postData)
+		sent = append(sent, delivery)
+	}
 
-	if err != nil {
-		logger.Warn("Failed querying index for dispatching", zap.Error(err))
-		return err
+	var c3poResponse C3poResponse
+	var err error
+	if len(posts) > 0 {
+		c3poResponse, err = sendToC3po(ctx, "/v1/data/post/batch", c3poBatchRequest, logger)
+		if errors.Is(err, errC3poEndpointUnsupported) {
+			return err
+		}
+	}
+	for i, delivery := range unreadable {
+		_ = finishDelivery(ctx, store, c3poSubscriber(), delivery, readErrors[i], logger)
+	}
+	results := map[string]C3poItemResponse{}
+	for _, result := range c3poResponse.Results {
+		results[result.FacebookID] = result
 	}
 
-	return nil
+	for i, postData := range posts {
+		postErr := err
+		if postErr == nil {
+			result, ok := results[postData.FacebookID]
+			switch {
+			case !ok:
+				postErr = errC3poNoBatchResult
+			case !result.Success && result.Error != "":
+				postErr = errors.New(result.Error)
+			case !result.Success:
+				postErr = errC3poParseFailed
+			}
+		}
+		if postErr != nil {
+			logger.Warn("Failed to parse", zap.String("postId", postData.FacebookID), zap.Error(postErr))
+		}
+		_ = finishDispatch(ctx, store, sent[i], postData, postErr, logger)
+	}
+	logger.Info("Dispatched batch to C-3PO", zap.Int("size", len(deliveries)), zap.Error(err))
+	return err
 }
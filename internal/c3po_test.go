@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/signature"
+	"go.uber.org/zap"
+)
+
+func newTestC3po(t *testing.T, success bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(C3poResponse{Success: success}); err != nil {
+			t.Errorf("Failed to encode C-3PO response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDispatchFreshPosts(t *testing.T) {
+	testCases := []struct {
+		name           string
+		success        bool
+		expectedStatus string
+	}{
+		{"success marks the delivery as delivered", true, webhookDeliveryDelivered},
+		{"failure keeps the delivery pending", false, webhookDeliveryPending},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := newTestC3po(t, testCase.success)
+			setTestConfig(t, func(c *Config) {
+				c.C3POURI = server.URL
+				c.Whoami = "test"
+			})
+
+			store := NewMemoryPostStore()
+			postData := PostData{
+				CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+				FacebookID:   "1_1",
+				FacebookPost: fb.Result{"id": "1_1"},
+			}
+			queueTestPost(t, store, postData)
+
+			if err := NewDispatcher(store, zap.NewNop()).Run(context.Background()); err != nil {
+				t.Fatalf("Dispatcher returned error: %v", err)
+			}
+			stored, err := store.GetPost(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != testCase.expectedStatus {
+				t.Errorf("Expected the C-3PO delivery to be %s, got %+v", testCase.expectedStatus, delivery)
+			}
+			if len(stored.DispatchHistory) != 1 || stored.DispatchHistory[0].Success != testCase.success {
+				t.Errorf("Expected one dispatch attempt with success=%v, got %+v", testCase.success, stored.DispatchHistory)
+			}
+		})
+	}
+}
+
+func TestDispatchItemForwardsComments(t *testing.T) {
+	var c3poRequest C3poRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&c3poRequest)
+		_ = json.NewEncoder(w).Encode(C3poResponse{Success: true})
+	}))
+	t.Cleanup(server.Close)
+	setTestConfig(t, func(c *Config) { c.C3POURI = server.URL })
+
+	store := NewMemoryPostStore()
+	postData := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"id": "1_1"},
+	}
+	queueTestPost(t, store, postData)
+	_ = store.UpdateOrInsertComment(context.Background(), CommentData{
+		PostID:          "1_1",
+		FacebookID:      "1_1_c1",
+		CreatedTime:     postData.CreatedTime,
+		FacebookComment: fb.Result{"id": "1_1_c1", "message": "https://youtu.be/dQw4w9WgXcQ"},
+	})
+
+	if err := dispatchItem(context.Background(), store, c3poDeliveryOf(t, store, "1_1"), zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if len(c3poRequest.Comments) != 1 || c3poRequest.Comments[0]["id"] != "1_1_c1" {
+		t.Errorf("Expected the comment to be sent along with the post, got %+v", c3poRequest.Comments)
+	}
+	if len(c3poRequest.Links) != 1 || c3poRequest.Links[0].URL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("Expected the music link of the comment to be sent along with the post, got %+v", c3poRequest.Links)
+	}
+}
+
+func TestSendToC3poSignsRequests(t *testing.T) {
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = signature.VerifyRequest(r, []byte("shared"))
+		_ = json.NewEncoder(w).Encode(C3poResponse{Success: true})
+	}))
+	t.Cleanup(server.Close)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = server.URL
+		c.C3POSigningSecret = "shared"
+	})
+
+	postData := PostData{FacebookID: "1_1", FacebookPost: fb.Result{"id": "1_1"}}
+	if _, err := postToC3po(context.Background(), postData, nil, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if verifyErr != nil {
+		t.Errorf("Expected C-3PO to verify the request signature, got %v", verifyErr)
+	}
+}
+
+func TestCancelledDispatchLeavesPostQueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The process is asked to stop while C-3PO is still handling the post
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = server.URL
+		c.Whoami = "test"
+	})
+
+	store := NewMemoryPostStore()
+	postData := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"id": "1_1"},
+	}
+	queueTestPost(t, store, postData)
+
+	if err := dispatchItem(ctx, store, c3poDeliveryOf(t, store, "1_1"), zap.NewNop()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the dispatch to be cancelled, got %v", err)
+	}
+	postData, err := store.GetPost(context.Background(), "1_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := c3poDeliveryOf(t, store, "1_1")
+	if delivery.Status != webhookDeliveryPending || delivery.Attempts != 0 || len(postData.DispatchHistory) != 0 {
+		t.Errorf("Expected the post to stay queued without a failed attempt, got %+v and %+v", delivery, postData)
+	}
+}
+
+func TestHungC3poTimesOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = server.URL
+		c.Whoami = "test"
+		c.RequestTimeout = 1
+	})
+
+	store := NewMemoryPostStore()
+	postData := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"id": "1_1"},
+	}
+	queueTestPost(t, store, postData)
+
+	if err := dispatchItem(context.Background(), store, c3poDeliveryOf(t, store, "1_1"), zap.NewNop()); err == nil {
+		t.Fatal("Expected the dispatch to time out")
+	}
+	if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Attempts != 1 || delivery.Status != webhookDeliveryPending {
+		t.Errorf("Expected the timeout to count as a failed attempt, got %+v", delivery)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	flags "github.com/jessevdk/go-flags"
+	"go.uber.org/zap"
+)
+
+// cli holds the options shared by every command of the r2-d2 command line
+type cli struct {
+	ConfigFile string `long:"config" env:"CONFIG_FILE" value-name:"FILE" description:"YAML configuration file, overridden by env variables"`
+
+	Serve       serveCommand       `command:"serve" description:"Run the scheduled jobs and the API server (default)"`
+	Fetch       fetchCommand       `command:"fetch" description:"Fetch the latest posts of the group"`
+	Dispatch    dispatchCommand    `command:"dispatch" description:"Send unparsed posts to C-3PO"`
+	Backfill    backfillCommand    `command:"backfill" description:"Store every post of the group back to a date"`
+	CreateTable createTableCommand `command:"create-table" description:"Create the DynamoDB tables, or the BoltDB file, if missing"`
+	Export      exportCommand      `command:"export" description:"Write stored posts as JSON lines"`
+	Token       tokenCommand       `command:"token" description:"Manage the Facebook access token"`
+
+	logger *zap.Logger
+}
+
+// newCLIParser parses the command line into app, running the selected command
+func newCLIParser(app *cli) *flags.Parser {
+	app.Serve.app = app
+	app.Fetch.app = app
+	app.Dispatch.app = app
+	app.Backfill.app = app
+	app.CreateTable.app = app
+	app.Export.app = app
+	app.Token.Refresh.app = app
+
+	parser := flags.NewNamedParser("r2-d2", flags.HelpFlag|flags.PassDoubleDash)
+	if _, err := parser.AddGroup("Application Options", "", app); err != nil {
+		panic(err)
+	}
+	parser.SubcommandsOptional = true
+	return parser
+}
+
+// run parses the command line arguments and runs the selected command, serving if there's none
+func (app *cli) run(args []string) error {
+	parser := newCLIParser(app)
+	rest, err := parser.ParseArgs(args)
+	if err != nil || parser.Active != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return &flags.Error{Type: flags.ErrUnknownCommand, Message: fmt.Sprintf("Unknown command `%s'", rest[0])}
+	}
+	return app.Serve.Execute(nil)
+}
+
+// start loads the configuration, checking C-3PO settings if usesC3po is set, and sets up what commands share:
+// tracing, webhook subscribers and the post store. The returned function closes the store and flushes pending
+// traces, so it's called once the jobs using the store returned.
+func (app *cli) start(usesC3po bool) (PostStore, func(), error) {
+	loadedConfig, err := LoadConfig(app.ConfigFile, usesC3po)
+	if err != nil {
+		return nil, nil, err
+	}
+	config = loadedConfig
+	app.logger.Info("Loaded configuration", zap.Object("config", config))
+
+	shutdownTracing, err := initTracing(app.logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("initializing tracing: %w", err)
+	}
+	done := func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			app.logger.Warn("Unable to flush pending traces", zap.Error(err))
+		}
+	}
+
+	webhookSubscribers, err = loadWebhookSubscribers(config.WebhookSubscribersFile)
+	if err != nil {
+		done()
+		return nil, nil, fmt.Errorf("loading webhook subscribers: %w", err)
+	}
+	app.logger.Info("Loaded webhook subscribers", zap.Int("count", len(webhookSubscribers)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout())
+	defer cancel()
+	store, err := InitializeStore(ctx, app.logger)
+	if err != nil {
+		done()
+		return nil, nil, fmt.Errorf("initializing post store: %w", err)
+	}
+	return store, func() {
+		closeStore(store, app.logger)
+		done()
+	}, nil
+}
+
+// runOnSchedule runs job once, or periodically until the process is interrupted. Either way, the job in flight is
+// cancelled when the process is asked to stop.
+func (app *cli) runOnSchedule(job scheduledJob, once bool) error {
+	if once {
+		ctx, stop := interruptContext()
+		defer stop()
+		return runJob(ctx, job, app.logger)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := scheduleJobs(ctx, []scheduledJob{job}, app.logger)
+	waitForInterrupt()
+	stopJobs(c, cancel, time.Duration(config.ShutdownTimeout)*time.Second, app.logger)
+	return nil
+}
+
+// waitForInterrupt blocks until the process is asked to stop
+func waitForInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	signal.Stop(signals)
+}
+
+// interruptContext returns a context cancelled when the process is asked to stop. The returned function releases
+// it, after which signals are handled as usual again.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+type serveCommand struct {
+	app *cli
+}
+
+// Execute schedules every job and serves the API until the process is asked to stop. Scheduled jobs and
+// backfills are then cancelled and waited for up to SHUTDOWN_TIMEOUT seconds, before the API server finishes the
+// requests in flight and the store is closed.
+func (cmd *serveCommand) Execute([]string) error {
+	store, done, err := cmd.app.start(true)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := cmd.app.logger
+	dispatcher := NewDispatcher(store, logger)
+	jobs := []scheduledJob{fetchJob(store, logger), dispatchJob(dispatcher), reconcileJob(store, logger)}
+	if len(webhookSubscribers) > 0 {
+		jobs = append(jobs, webhooksJob(store, logger))
+	}
+	c := scheduleJobs(ctx, jobs, logger)
+	backfills := &backfillRunner{ctx: ctx, backfill: Backfill}
+
+	// Start API server. Jobs and backfills share the shutdown deadline.
+	stop := func() {
+		timeout := time.Duration(config.ShutdownTimeout) * time.Second
+		deadline := time.Now().Add(timeout)
+		stopJobs(c, cancel, timeout, logger)
+		if !backfills.Wait(time.Until(deadline)) {
+			logger.Warn("Timed out waiting for the backfill and webhook notifications to stop", zap.Duration("timeout", timeout))
+		}
+	}
+	return initializeAPIServer(store, dispatcher, backfills, stop, logger)
+}
+
+type fetchCommand struct {
+	Once bool `long:"once" description:"Fetch once and exit, instead of every FB_FETCH_FREQUENCY seconds"`
+	app  *cli
+}
+
+// Execute fetches the latest posts of the group
+func (cmd *fetchCommand) Execute([]string) error {
+	store, done, err := cmd.app.start(false)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return cmd.app.runOnSchedule(fetchJob(store, cmd.app.logger), cmd.Once)
+}
+
+type dispatchCommand struct {
+	Once bool `long:"once" description:"Dispatch once and exit, instead of every DISPATCHER_FREQUENCY seconds"`
+	app  *cli
+}
+
+// Execute sends unparsed posts to C-3PO
+func (cmd *dispatchCommand) Execute([]string) error {
+	store, done, err := cmd.app.start(true)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return cmd.app.runOnSchedule(dispatchJob(NewDispatcher(store, cmd.app.logger)), cmd.Once)
+}
+
+type backfillCommand struct {
+	Since string `long:"since" required:"true" value-name:"DATE" description:"Oldest creation date of the posts to store, as YYYY-MM-DD or RFC3339"`
+	app   *cli
+}
+
+// Execute backfills the group feed, resuming the previous backfill back to the same date if it was interrupted
+func (cmd *backfillCommand) Execute([]string) error {
+	since, err := parseBackfillSince(cmd.Since)
+	if err != nil {
+		return fmt.Errorf("invalid backfill date %q: %w", cmd.Since, err)
+	}
+	store, done, err := cmd.app.start(false)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ctx, stop := interruptContext()
+	defer stop()
+	return Backfill(ctx, store, since, cmd.app.logger)
+}
+
+type createTableCommand struct {
+	app *cli
+}
+
+// Execute creates the storage of the configured backend, which every other command also does when it's missing
+func (cmd *createTableCommand) Execute([]string) error {
+	_, done, err := cmd.app.start(false)
+	if err != nil {
+		return err
+	}
+	defer done()
+	cmd.app.logger.Info("Storage is ready", zap.String("backend", config.StorageBackend))
+	return nil
+}
+
+type exportCommand struct {
+	Output string `long:"output" short:"o" default:"-" value-name:"FILE" description:"File to write to, - for stdout"`
+	Since  string `long:"since" value-name:"DATE" description:"Only export posts created since this date, as YYYY-MM-DD or RFC3339"`
+	app    *cli
+}
+
+// Execute writes every stored post as a JSON object per line
+func (cmd *exportCommand) Execute([]string) error {
+	query := PostQuery{Limit: 100}
+	if cmd.Since != "" {
+		since, err := parseBackfillSince(cmd.Since)
+		if err != nil {
+			return fmt.Errorf("invalid export date %q: %w", cmd.Since, err)
+		}
+		query.CreatedAfter = since
+	}
+	store, done, err := cmd.app.start(false)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	var output io.Writer = os.Stdout
+	if cmd.Output != "-" {
+		file, err := os.Create(cmd.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	exported, err := exportPosts(ctx, store, query, output)
+	cmd.app.logger.Info("Exported posts", zap.Int("count", exported), zap.Error(err))
+	return err
+}
+
+// exportPosts writes the posts matching query as JSON lines, and returns how many were written
+func exportPosts(ctx context.Context, store PostStore, query PostQuery, output io.Writer) (int, error) {
+	encoder := json.NewEncoder(output)
+	exported := 0
+	for {
+		page, err := store.ListPosts(ctx, query)
+		if err != nil {
+			return exported, err
+		}
+		for _, postData := range page.Posts {
+			if err := encoder.Encode(postData); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if page.NextCursor == "" {
+			return exported, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+type tokenCommand struct {
+	Refresh tokenRefreshCommand `command:"refresh" description:"Exchange the Facebook access token for a new long-lived one"`
+}
+
+type tokenRefreshCommand struct {
+	app *cli
+}
+
+// Execute exchanges the current access token for a new long-lived one, saved to the .env file if there's one and
+// printed otherwise
+func (cmd *tokenRefreshCommand) Execute([]string) error {
+	loadedConfig, err := LoadConfig(cmd.app.ConfigFile, false)
+	if err != nil {
+		return err
+	}
+	config = loadedConfig
+
+	accessToken := config.FBLongAccessToken
+	if accessToken == "" {
+		accessToken = config.FBShortAccessToken
+	}
+	if accessToken == "" {
+		return errors.New("FB_SHORT_ACCESS_TOKEN or FB_LONG_ACCESS_TOKEN is required")
+	}
+	longAccessToken, expires, err := fb.New(config.FBAppID, config.FBAppSecret).ExchangeToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	expiresIn := zap.Duration("expiresIn", time.Duration(expires)*time.Second)
+	if !fileExists(envFile) {
+		cmd.app.logger.Info("Refreshed Facebook access token, set it as FB_LONG_ACCESS_TOKEN", expiresIn)
+		fmt.Println(longAccessToken)
+		return nil
+	}
+	saveFbLongAccessToken(longAccessToken)
+	cmd.app.logger.Info("Refreshed Facebook access token, saved to the .env file", expiresIn)
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	flags "github.com/jessevdk/go-flags"
+	"go.uber.org/zap"
+)
+
+func TestCLIParser(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"backfill"}, "`--since' was not specified"},
+		{[]string{"backfill", "--since", "last week"}, `invalid backfill date "last week"`},
+		{[]string{"export", "--since", "yesterday"}, `invalid export date "yesterday"`},
+		{[]string{"token"}, "specify the refresh command"},
+		{[]string{"fetch", "--twice"}, "unknown flag `twice'"},
+		{[]string{"deploy"}, "Unknown command `deploy'"},
+	}
+	for _, testCase := range testCases {
+		t.Run(strings.Join(testCase.args, " "), func(t *testing.T) {
+			err := (&cli{logger: zap.NewNop()}).run(testCase.args)
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("Expected an error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+
+	err := (&cli{logger: zap.NewNop()}).run([]string{"--help"})
+	var flagsErr *flags.Error
+	if !errors.As(err, &flagsErr) || flagsErr.Type != flags.ErrHelp || !strings.Contains(flagsErr.Message, "create-table") {
+		t.Errorf("Expected help to list the commands, got %v", err)
+	}
+}
+
+func TestExportPosts(t *testing.T) {
+	store := NewMemoryPostStore()
+	for i := 1; i <= 5; i++ {
+		err := store.UpdateOrInsertPost(context.Background(), PostData{
+			CreatedTime: time.Date(2020, 10, i, 0, 0, 0, 0, time.UTC),
+			FacebookID:  fmt.Sprintf("1_%d", i),
+			UpdatedTime: time.Date(2020, 10, i, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var output bytes.Buffer
+	exported, err := exportPosts(context.Background(), store, PostQuery{Limit: 2, CreatedAfter: time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)}, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exportedIDs := map[string]bool{}
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var postData PostData
+		if err := json.Unmarshal(scanner.Bytes(), &postData); err != nil {
+			t.Fatalf("Expected a post per line, got %q: %v", scanner.Text(), err)
+		}
+		exportedIDs[postData.FacebookID] = true
+	}
+	if exported != 4 || len(exportedIDs) != 4 || exportedIDs["1_1"] {
+		t.Errorf("Expected the 4 posts created since the date to be exported across pages, got %d: %v", exported, exportedIDs)
+	}
+}
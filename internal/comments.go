@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cenkalti/backoff/v4"
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// The stream filter returns replies along with top-level comments, each with its `parent`
+var fbCommentParams = fb.Params{
+	"fields": "id,attachment,comment_count,created_time,from,like_count,message,message_tags,parent",
+	"filter": "stream",
+	"order":  "chronological",
+	"limit":  "100",
+}
+
+// fetchComments pages through the whole comment thread of a post
+func fetchComments(fbSession *fb.Session, postID string, logger *zap.Logger) ([]CommentData, error) {
+	// Configure exponential backoff for retries
+	exponentialBackoff := graphBackOff(fbSession)
+
+	// Fetch the first page of response
+	var commentsResp fb.Result
+	err := backoff.RetryNotify(func() error {
+		var fbError error
+		commentsResp, fbError = fbSession.Get(fmt.Sprintf("%s/comments", postID), fbCommentParams)
+		countGraphError(fbError)
+		return fbError
+	}, exponentialBackoff, retryNotifyFunc)
+	if err != nil {
+		logger.Warn("Unable to schedule retry for comments fetch", zap.String("PostID", postID), zap.Error(err))
+		return nil, err
+	}
+	paging, err := commentsResp.Paging(fbSession)
+	if err != nil {
+		logger.Warn("Comments result can't be used for paging", zap.String("PostID", postID), zap.Error(err))
+		return nil, err
+	}
+
+	var comments []CommentData
+	for {
+		for _, comment := range paging.Data() {
+			var commentMetadata CommentMetadata
+			if err := comment.Decode(&commentMetadata); err != nil {
+				logger.Error("Failed to decode key metadata from Facebook comment", zap.String("PostID", postID), zap.Error(err))
+				continue
+			}
+			comments = append(comments, CommentData{
+				PostID:          postID,
+				FacebookID:      commentMetadata.FacebookID,
+				ParentID:        commentMetadata.Parent.FacebookID,
+				CreatedTime:     commentMetadata.CreatedTime,
+				FacebookComment: comment,
+			})
+		}
+
+		// Break on last page
+		var noMore bool
+		err := backoff.RetryNotify(func() error {
+			var pagingError error
+			noMore, pagingError = paging.Next()
+			countGraphError(pagingError)
+			return pagingError
+		}, exponentialBackoff, retryNotifyFunc)
+		if err != nil {
+			logger.Error("Failed paging through Facebook comments", zap.String("PostID", postID), zap.Error(err))
+			return nil, err
+		}
+		if noMore {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// ingestComments fetches the comment thread of a post and stores every comment. Stored comments missing from the
+// thread were deleted or hidden since, and are removed.
+func ingestComments(ctx context.Context, fbSession *fb.Session, store PostStore, postID string, logger *zap.Logger) error {
+	comments, err := fetchComments(fbSession, postID, logger)
+	if err != nil {
+		return err
+	}
+	fetchedIDs := map[string]bool{}
+	for _, comment := range comments {
+		if err := store.UpdateOrInsertComment(ctx, comment); err != nil {
+			return err
+		}
+		fetchedIDs[comment.FacebookID] = true
+	}
+
+	storedComments, err := store.ListComments(ctx, postID)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, comment := range storedComments {
+		if fetchedIDs[comment.FacebookID] {
+			continue
+		}
+		if err := store.DeleteComment(ctx, postID, comment.FacebookID); err != nil {
+			return err
+		}
+		removed++
+	}
+	logger.Debug("Stored comments of post", zap.String("PostID", postID), zap.Int("count", len(comments)), zap.Int("removed", removed))
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/fakegraph"
+	"go.uber.org/zap"
+)
+
+func TestFetchCommentsPaging(t *testing.T) {
+	graph := fakegraph.New()
+	defer graph.Close()
+	graph.SetCommentPageSize(2)
+	postID := fbGroupID + "_1"
+	graph.AddComments(postID,
+		fb.Result{"id": "c1", "created_time": "2020-10-01T10:00:00+00:00", "message": "first"},
+		fb.Result{"id": "c2", "created_time": "2020-10-01T10:05:00+00:00", "message": "reply", "parent": fb.Result{"id": "c1"}},
+		fb.Result{"id": "c3", "created_time": "2020-10-01T10:10:00+00:00", "message": "reply", "parent": fb.Result{"id": "c1"}},
+		fb.Result{"id": "c4", "created_time": "2020-10-01T10:15:00+00:00", "message": "second"},
+		fb.Result{"id": "c5", "created_time": "2020-10-01T10:20:00+00:00", "message": "reply", "parent": fb.Result{"id": "c4"}},
+	)
+
+	comments, err := fetchComments(graph.Session(), postID, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var threads []string
+	for _, comment := range comments {
+		threads = append(threads, comment.ParentID+">"+comment.FacebookID)
+	}
+	if strings.Join(threads, ",") != ">c1,c1>c2,c1>c3,>c4,c4>c5" {
+		t.Errorf("Expected every comment with its parent, got %v", threads)
+	}
+	var cursors []string
+	for _, request := range graph.Requests() {
+		if strings.HasSuffix(request.Path, "/comments") {
+			cursors = append(cursors, request.Query.Get("after"))
+			if request.Query.Get("filter") != "stream" {
+				t.Errorf("Expected every page to keep the stream filter, got %v", request.Query)
+			}
+		}
+	}
+	if strings.Join(cursors, ",") != ",2,4" {
+		t.Errorf("Expected the 3 pages of the thread to be requested once, requested %v", cursors)
+	}
+
+	// Comments are stored under their post
+	store := NewMemoryPostStore()
+	if err := ingestComments(context.Background(), graph.Session(), store, postID, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.ListComments(context.Background(), postID); len(stored) != 5 {
+		t.Errorf("Expected the whole thread to be stored, got %+v", stored)
+	}
+}
+
+func TestIngestCommentsRemovesDeletedComments(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			graph := fakegraph.New()
+			defer graph.Close()
+			graph.SetCommentPageSize(2)
+			postID := fbGroupID + "_1"
+			graph.AddComments(postID,
+				fb.Result{"id": "c1", "created_time": "2020-10-01T10:00:00+00:00", "message": "first"},
+				fb.Result{"id": "c2", "created_time": "2020-10-01T10:05:00+00:00", "message": "reply", "parent": fb.Result{"id": "c1"}},
+				fb.Result{"id": "c3", "created_time": "2020-10-01T10:10:00+00:00", "message": "second"},
+			)
+			if err := ingestComments(context.Background(), graph.Session(), store, postID, zap.NewNop()); err != nil {
+				t.Fatal(err)
+			}
+
+			graph.DeleteComment(postID, "c2")
+			if err := ingestComments(context.Background(), graph.Session(), store, postID, zap.NewNop()); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := store.ListComments(context.Background(), postID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var storedIDs []string
+			for _, comment := range stored {
+				storedIDs = append(storedIDs, comment.FacebookID)
+			}
+			if strings.Join(storedIDs, ",") != "c1,c3" {
+				t.Errorf("Expected the deleted comment to be removed, got %v", storedIDs)
+			}
+		})
+	}
+}
@@ -1,18 +1,277 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"net/url"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
 )
 
-// GetEnv returns the environment variable with key `key`
-func GetEnv(key string, defaultVal string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Config holds the settings of R2-D2. Every field is named by its `env` tag, which is also its key in the YAML
+// configuration file once lower-cased. Fields tagged `secret` are redacted when logged.
+type Config struct {
+	// Scheduler
+	FBFetchFrequency             int     `env:"FB_FETCH_FREQUENCY"`
+	DispatcherFrequency          int     `env:"DISPATCHER_FREQUENCY"`
+	LatestCheckThreshold         int     `env:"LATEST_CHECK_THRESHOLD"`
+	ReconcileFrequency           int     `env:"RECONCILE_FREQUENCY"`
+	DispatcherConcurrency        int     `env:"DISPATCHER_CONCURRENCY"`
+	DispatcherRateLimit          float64 `env:"DISPATCHER_RATE_LIMIT"`
+	DispatcherBatchSize          int     `env:"DISPATCHER_BATCH_SIZE"`
+	DispatchMaxAttempts          int     `env:"DISPATCH_MAX_ATTEMPTS"`
+	DispatchRetryInterval        int     `env:"DISPATCH_RETRY_INTERVAL"`
+	ReconcileWindowDays          int     `env:"RECONCILE_WINDOW_DAYS"`
+	EngagementSnapshotMaxAgeDays int     `env:"ENGAGEMENT_SNAPSHOT_MAX_AGE_DAYS"`
+	ShutdownTimeout              int     `env:"SHUTDOWN_TIMEOUT"`
+	JobTimeout                   int     `env:"JOB_TIMEOUT"`
+	RequestTimeout               int     `env:"REQUEST_TIMEOUT"`
+
+	// Storage
+	StorageBackend     string `env:"STORAGE_BACKEND"`
+	BoltPath           string `env:"BOLT_PATH"`
+	AWSAccessKeyID     string `env:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string `env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	AWSRegion          string `env:"AWS_REGION"`
+	DynamoDBEndpoint   string `env:"DYNAMODB_ENDPOINT"`
+
+	// C-3PO and webhook subscribers
+	C3POURI                  string `env:"C3PO_URI"`
+	C3POSigningSecret        string `env:"C3PO_SIGNING_SECRET" secret:"true"`
+	Whoami                   string `env:"WHOAMI" secret:"true"`
+	WebhookSubscribersFile   string `env:"WEBHOOK_SUBSCRIBERS_FILE"`
+	WebhookDeliveryFrequency int    `env:"WEBHOOK_DELIVERY_FREQUENCY"`
+	WebhookMaxAttempts       int    `env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetryInterval     int    `env:"WEBHOOK_RETRY_INTERVAL"`
+
+	// Facebook
+	FBAppID              string `env:"FB_APP_ID"`
+	FBAppSecret          string `env:"FB_APP_SECRET" secret:"true"`
+	FBShortAccessToken   string `env:"FB_SHORT_ACCESS_TOKEN" secret:"true"`
+	FBLongAccessToken    string `env:"FB_LONG_ACCESS_TOKEN" secret:"true"`
+	FBGraphURL           string `env:"FB_GRAPH_URL"`
+	FBWebhookVerifyToken string `env:"FB_WEBHOOK_VERIFY_TOKEN" secret:"true"`
+
+	// API
+	AdminToken string `env:"ADMIN_TOKEN" secret:"true"`
+
+	// Tracing
+	TracingExporter string `env:"TRACING_EXPORTER"`
+	OTLPEndpoint    string `env:"OTLP_ENDPOINT"`
+	OTLPInsecure    bool   `env:"OTLP_INSECURE"`
+}
+
+// config is the configuration in use, replaced by the one loaded at startup
+var config = defaultConfig()
+
+// defaultConfig returns the settings used when neither the env nor the configuration file set them
+func defaultConfig() Config {
+	return Config{
+		FBFetchFrequency:             300,
+		DispatcherFrequency:          150,
+		LatestCheckThreshold:         300,
+		ReconcileFrequency:           3600,
+		DispatcherConcurrency:        4,
+		DispatcherRateLimit:          5,
+		DispatcherBatchSize:          1,
+		DispatchMaxAttempts:          5,
+		DispatchRetryInterval:        60,
+		ReconcileWindowDays:          7,
+		EngagementSnapshotMaxAgeDays: 7,
+		ShutdownTimeout:              20,
+		JobTimeout:                   600,
+		RequestTimeout:               30,
+		StorageBackend:               "dynamodb",
+		BoltPath:                     "r2d2.db",
+		AWSAccessKeyID:               "DEFAULT_KEY",
+		AWSSecretAccessKey:           "DEFAULT_SECRET",
+		AWSRegion:                    "ap-south-1",
+		WebhookDeliveryFrequency:     60,
+		WebhookMaxAttempts:           5,
+		WebhookRetryInterval:         60,
+		OTLPEndpoint:                 "localhost:4317",
+	}
+}
+
+// LoadConfig reads the configuration from the YAML file at path, if any, then from env variables, which take
+// precedence. Variables of the `.env` file are already part of the env. Empty values are ignored, so that settings
+// left blank keep their default. Every invalid setting is reported in the returned error, as well as missing C-3PO
+// settings if usesC3po is set.
+func LoadConfig(path string, usesC3po bool) (Config, error) {
+	values := map[string]string{}
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		var fileValues map[string]string
+		if err := yaml.Unmarshal(content, &fileValues); err != nil {
+			return Config{}, fmt.Errorf("invalid configuration file %s: %w", path, err)
+		}
+		for key, value := range fileValues {
+			values[strings.ToUpper(key)] = value
+		}
+	}
+
+	c := defaultConfig()
+	var problems []string
+	known := map[string]bool{}
+	configValue := reflect.ValueOf(&c).Elem()
+	for i := 0; i < configValue.NumField(); i++ {
+		key := configValue.Type().Field(i).Tag.Get("env")
+		known[key] = true
+		value := values[key]
+		if envValue, exists := os.LookupEnv(key); exists && envValue != "" {
+			value = envValue
+		}
+		if value == "" {
+			continue
+		}
+		if err := setConfigField(configValue.Field(i), value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s, got %q", key, err, value))
+		}
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown setting %s in %s", key, path))
+	}
+
+	problems = append(problems, c.validate()...)
+	if usesC3po {
+		problems = append(problems, c.validateC3po()...)
+	}
+	if len(problems) > 0 {
+		return c, errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return c, nil
+}
+
+// setConfigField parses value into a field of Config
+func setConfigField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(parsed)
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// validate returns the problems with settings that parsed but can't be used
+func (c Config) validate() []string {
+	var problems []string
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"FB_FETCH_FREQUENCY", c.FBFetchFrequency},
+		{"DISPATCHER_FREQUENCY", c.DispatcherFrequency},
+		{"LATEST_CHECK_THRESHOLD", c.LatestCheckThreshold},
+		{"RECONCILE_FREQUENCY", c.ReconcileFrequency},
+		{"DISPATCHER_CONCURRENCY", c.DispatcherConcurrency},
+		{"DISPATCHER_BATCH_SIZE", c.DispatcherBatchSize},
+		{"DISPATCH_MAX_ATTEMPTS", c.DispatchMaxAttempts},
+		{"DISPATCH_RETRY_INTERVAL", c.DispatchRetryInterval},
+		{"RECONCILE_WINDOW_DAYS", c.ReconcileWindowDays},
+		{"ENGAGEMENT_SNAPSHOT_MAX_AGE_DAYS", c.EngagementSnapshotMaxAgeDays},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"JOB_TIMEOUT", c.JobTimeout},
+		{"REQUEST_TIMEOUT", c.RequestTimeout},
+		{"WEBHOOK_DELIVERY_FREQUENCY", c.WebhookDeliveryFrequency},
+		{"WEBHOOK_MAX_ATTEMPTS", c.WebhookMaxAttempts},
+		{"WEBHOOK_RETRY_INTERVAL", c.WebhookRetryInterval},
+	} {
+		if setting.value < 1 {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %d", setting.key, setting.value))
+		}
+	}
+	if c.DispatcherRateLimit <= 0 {
+		problems = append(problems, fmt.Sprintf("DISPATCHER_RATE_LIMIT must be positive, got %v", c.DispatcherRateLimit))
+	}
+
+	switch c.StorageBackend {
+	case "dynamodb", "bolt", "memory":
+	default:
+		problems = append(problems, fmt.Sprintf("STORAGE_BACKEND must be dynamodb, bolt or memory, got %q", c.StorageBackend))
+	}
+	switch c.TracingExporter {
+	case "", "stdout", "otlp":
+	default:
+		problems = append(problems, fmt.Sprintf("TRACING_EXPORTER must be stdout or otlp, got %q", c.TracingExporter))
+	}
+	return problems
+}
+
+// validateC3po returns the problems with the settings needed to reach C-3PO
+func (c Config) validateC3po() []string {
+	var problems []string
+	if c.C3POURI == "" {
+		problems = append(problems, "C3PO_URI is required")
+	} else if uri, err := url.Parse(c.C3POURI); err != nil || uri.Scheme == "" || uri.Host == "" {
+		problems = append(problems, fmt.Sprintf("C3PO_URI must be an absolute URL, got %q", c.C3POURI))
+	}
+	if c.C3POSigningSecret == "" && c.Whoami == "" {
+		problems = append(problems, "C3PO_SIGNING_SECRET or WHOAMI is required")
+	}
+	return problems
+}
+
+// MarshalLogObject logs every setting, with secrets redacted
+func (c Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	configValue := reflect.ValueOf(c)
+	for i := 0; i < configValue.NumField(); i++ {
+		field := configValue.Type().Field(i)
+		key := field.Tag.Get("env")
+		value := configValue.Field(i)
+		switch {
+		case field.Tag.Get("secret") != "":
+			if value.String() != "" {
+				enc.AddString(key, "[redacted]")
+			} else {
+				enc.AddString(key, "")
+			}
+		case value.Kind() == reflect.Int:
+			enc.AddInt64(key, value.Int())
+		case value.Kind() == reflect.Float64:
+			enc.AddFloat64(key, value.Float())
+		case value.Kind() == reflect.Bool:
+			enc.AddBool(key, value.Bool())
+		default:
+			enc.AddString(key, value.String())
+		}
 	}
-	return defaultVal
+	return nil
 }
 
 // GetLogger fetches the global logging config
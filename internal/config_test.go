@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// setTestConfig changes the configuration in use until the end of the test
+func setTestConfig(t *testing.T, change func(c *Config)) {
+	previous := config
+	t.Cleanup(func() { config = previous })
+	change(&config)
+}
+
+// setTestEnv sets env variables until the end of the test
+func setTestEnv(t *testing.T, env map[string]string) {
+	for key, value := range env {
+		key := key
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.Unsetenv(key) })
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "r2d2.yml")
+	content := "c3po_uri: http://c3po:8000\nc3po_signing_secret: file\nfb_fetch_frequency: 60\notlp_insecure: true\n"
+	if err := ioutil.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setTestEnv(t, map[string]string{"C3PO_SIGNING_SECRET": "env", "DISPATCHER_RATE_LIMIT": "2.5", "BOLT_PATH": ""})
+
+	c, err := LoadConfig(configFile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := defaultConfig()
+	expected.C3POURI = "http://c3po:8000"
+	expected.C3POSigningSecret = "env"
+	expected.FBFetchFrequency = 60
+	expected.OTLPInsecure = true
+	expected.DispatcherRateLimit = 2.5
+	if c != expected {
+		t.Errorf("Expected env variables over the file over defaults, got %+v", c)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		file     string
+		expected []string
+	}{
+		{"missing C-3PO", map[string]string{"WHOAMI": "test"}, "", []string{"C3PO_URI is required"}},
+		{"relative C-3PO URI", map[string]string{"C3PO_URI": "c3po:8000", "WHOAMI": "test"}, "",
+			[]string{`C3PO_URI must be an absolute URL, got "c3po:8000"`}},
+		{"missing credentials", map[string]string{"C3PO_URI": "http://c3po"}, "", []string{"C3PO_SIGNING_SECRET or WHOAMI is required"}},
+		{"non-numeric settings", map[string]string{"C3PO_URI": "http://c3po", "WHOAMI": "test", "FB_FETCH_FREQUENCY": "5m", "LATEST_CHECK_THRESHOLD": "all"}, "",
+			[]string{`FB_FETCH_FREQUENCY must be a whole number, got "5m"`, `LATEST_CHECK_THRESHOLD must be a whole number, got "all"`}},
+		{"out of range", map[string]string{"C3PO_URI": "http://c3po", "WHOAMI": "test", "DISPATCHER_FREQUENCY": "0", "STORAGE_BACKEND": "s3"}, "",
+			[]string{"DISPATCHER_FREQUENCY must be positive, got 0", `STORAGE_BACKEND must be dynamodb, bolt or memory, got "s3"`}},
+		{"unknown setting", map[string]string{"C3PO_URI": "http://c3po", "WHOAMI": "test"}, "fetch_frequency: 60\n",
+			[]string{"unknown setting fetch_frequency"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setTestEnv(t, testCase.env)
+			configFile := ""
+			if testCase.file != "" {
+				configFile = filepath.Join(t.TempDir(), "r2d2.yml")
+				if err := ioutil.WriteFile(configFile, []byte(testCase.file), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := LoadConfig(configFile, true)
+			if err == nil {
+				t.Fatal("Expected the configuration to be rejected")
+			}
+			for _, problem := range testCase.expected {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("Expected %q to be reported, got %v", problem, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithoutC3po(t *testing.T) {
+	if _, err := LoadConfig("", false); err != nil {
+		t.Errorf("Expected C-3PO settings to be optional for commands that don't use it, got %v", err)
+	}
+	if _, err := LoadConfig("", true); err == nil || !strings.Contains(err.Error(), "C3PO_URI is required") {
+		t.Errorf("Expected C3PO_URI to be required, got %v", err)
+	}
+}
+
+func TestConfigRedactsSecrets(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	c := defaultConfig()
+	c.C3POURI = "http://c3po:8000"
+	c.C3POSigningSecret = "hunter2"
+	zap.New(core).Info("Loaded configuration", zap.Object("config", c))
+
+	logged := logs.All()[0].ContextMap()["config"].(map[string]interface{})
+	if logged["C3PO_SIGNING_SECRET"] != "[redacted]" || logged["C3PO_URI"] != "http://c3po:8000" {
+		t.Errorf("Expected secrets to be redacted, got %v", logged)
+	}
+	if logged["WHOAMI"] != "" || logged["FB_FETCH_FREQUENCY"] != int64(300) {
+		t.Errorf("Expected unset secrets and other settings as is, got %v", logged)
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -20,28 +22,62 @@ var sortKey = "created_time"
 var tableName = "feed"
 var partitionKey = "facebook_id"
 
-/// GSI
+/// GSI listing posts by created_time. Every post shares the same partition, set when it is stored.
+var createdTimeGsiIndexName = "created_time_index"
+var createdTimeGsiPartitionKey = "feed_partition"
+var createdTimeGsiPartitionValue = "posts"
+
+/// Former GSI of the queued posts, only read to move them over to C-3PO deliveries
 var parsedGsiIndexName = "parsed_index"
 var parsedGsiPartitionKey = "is_parsed"
 
+/// Former dispatch state attributes, removed along with is_parsed
+var dispatchAttemptsKey = "dispatch_attempts"
+var lastDispatchErrorKey = "last_dispatch_error"
+var nextDispatchTimeKey = "next_dispatch_time"
+
+/// Dispatch history attribute
+var dispatchHistoryKey = "dispatch_history"
+
+/// Comments table
+var commentsTableName = "comments"
+var commentsPartitionKey = "post_id"
+
+/// Engagement snapshots table
+var engagementTableName = "engagement_snapshots"
+var engagementSortKey = "sampled_at"
+
+/// Job state table
+var jobStateTableName = "job_state"
+var jobStatePartitionKey = "job_name"
+var backfillJobName = "backfill"
+
+/// Webhook deliveries table, with a GSI to find pending deliveries
+var webhookDeliveriesTableName = "webhook_deliveries"
+var webhookDeliveriesSortKey = "subscriber"
+var webhookStatusGsiIndexName = "status_index"
+var webhookStatusGsiPartitionKey = "status"
+var webhookStatusGsiSortKey = "updated_at"
+
 func createDynamoSession() *dynamodb.DynamoDB {
 	// Sensible defaults useful for local development
-	awsAccessKey := GetEnv("AWS_ACCESS_KEY_ID", "DEFAULT_KEY")
-	awsSecretKey := GetEnv("AWS_SECRET_ACCESS_KEY", "DEFAULT_SECRET")
-	awsDefaultRegion := GetEnv("AWS_REGION", "ap-south-1")
-	dynamoEndpoint := GetEnv("DYNAMODB_ENDPOINT", "")
+	awsAccessKey := config.AWSAccessKeyID
+	awsSecretKey := config.AWSSecretAccessKey
+	awsDefaultRegion := config.AWSRegion
+	dynamoEndpoint := config.DynamoDBEndpoint
 
 	if dynamoEndpoint != "" {
 		return dynamodb.New(session.Must(session.NewSession(&aws.Config{
 			Credentials: credentials.NewStaticCredentials(awsAccessKey, awsSecretKey, ""),
 			Endpoint:    aws.String(dynamoEndpoint),
+			HTTPClient:  newHTTPClient(),
 			Region:      aws.String(awsDefaultRegion),
 		})))
 	}
-	return dynamodb.New(session.Must(session.NewSession()))
+	return dynamodb.New(session.Must(session.NewSession(&aws.Config{HTTPClient: newHTTPClient()})))
 }
 
-func createTable(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+func createTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
 	tableCreateInput := dynamodb.CreateTableInput{
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{
 			{
@@ -53,21 +89,194 @@ func createTable(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
 				AttributeType: aws.String("S"),
 			},
 			{
-				AttributeName: aws.String(parsedGsiPartitionKey),
+				AttributeName: aws.String(createdTimeGsiPartitionKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		BillingMode:            aws.String("PROVISIONED"),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{createdTimeIndex()},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(partitionKey),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String(sortKey),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+		TableName: aws.String(tableName),
+	}
+	_, err := dynamoSession.CreateTableWithContext(ctx, &tableCreateInput)
+	if err != nil {
+		logger.Error("Failed creating table", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// createdTimeIndex describes the GSI listing posts by created_time
+func createdTimeIndex() *dynamodb.GlobalSecondaryIndex {
+	return &dynamodb.GlobalSecondaryIndex{
+		IndexName: aws.String(createdTimeGsiIndexName),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(createdTimeGsiPartitionKey),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String(sortKey),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		Projection: &dynamodb.Projection{
+			ProjectionType: aws.String("ALL"),
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+	}
+}
+
+func createCommentsTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	tableCreateInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(commentsPartitionKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(partitionKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		BillingMode: aws.String("PROVISIONED"),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(commentsPartitionKey),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String(partitionKey),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+		TableName: aws.String(commentsTableName),
+	}
+	_, err := dynamoSession.CreateTableWithContext(ctx, &tableCreateInput)
+	if err != nil {
+		logger.Error("Failed creating comments table", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func createEngagementTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	tableCreateInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(partitionKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(engagementSortKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		BillingMode: aws.String("PROVISIONED"),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(partitionKey),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String(engagementSortKey),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+		TableName: aws.String(engagementTableName),
+	}
+	_, err := dynamoSession.CreateTableWithContext(ctx, &tableCreateInput)
+	if err != nil {
+		logger.Error("Failed creating engagement snapshots table", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func createJobStateTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	tableCreateInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(jobStatePartitionKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		BillingMode: aws.String("PROVISIONED"),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(jobStatePartitionKey),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+		TableName: aws.String(jobStateTableName),
+	}
+	_, err := dynamoSession.CreateTableWithContext(ctx, &tableCreateInput)
+	if err != nil {
+		logger.Error("Failed creating job state table", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func createWebhookDeliveriesTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	tableCreateInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(partitionKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(webhookDeliveriesSortKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(webhookStatusGsiPartitionKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(webhookStatusGsiSortKey),
 				AttributeType: aws.String("S"),
 			},
 		},
 		BillingMode: aws.String("PROVISIONED"),
 		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
 			{
-				IndexName: aws.String(parsedGsiIndexName),
+				IndexName: aws.String(webhookStatusGsiIndexName),
 				KeySchema: []*dynamodb.KeySchemaElement{
 					{
-						AttributeName: aws.String(parsedGsiPartitionKey),
+						AttributeName: aws.String(webhookStatusGsiPartitionKey),
 						KeyType:       aws.String("HASH"),
 					},
 					{
-						AttributeName: aws.String(sortKey),
+						AttributeName: aws.String(webhookStatusGsiSortKey),
 						KeyType:       aws.String("RANGE"),
 					},
 				},
@@ -86,7 +295,7 @@ func createTable(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
 				KeyType:       aws.String("HASH"),
 			},
 			{
-				AttributeName: aws.String(sortKey),
+				AttributeName: aws.String(webhookDeliveriesSortKey),
 				KeyType:       aws.String("RANGE"),
 			},
 		},
@@ -94,35 +303,226 @@ func createTable(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
 			ReadCapacityUnits:  aws.Int64(1),
 			WriteCapacityUnits: aws.Int64(1),
 		},
-		TableName: aws.String(tableName),
+		TableName: aws.String(webhookDeliveriesTableName),
 	}
-	_, err := dynamoSession.CreateTable(&tableCreateInput)
+	_, err := dynamoSession.CreateTableWithContext(ctx, &tableCreateInput)
 	if err != nil {
-		logger.Error("Failed creating table", zap.Error(err))
+		logger.Error("Failed creating webhook deliveries table", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-// InitializeDynamoSession creates a DynamoDB session
-func InitializeDynamoSession(logger *zap.Logger) (*dynamodb.DynamoDB, error) {
-	dynamoSession := createDynamoSession()
-	_, err := dynamoSession.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+// ensureTable creates a table with createFunc if it doesn't exist yet
+func ensureTable(ctx context.Context, dynamoSession *dynamodb.DynamoDB, name string, createFunc func(context.Context, *dynamodb.DynamoDB, *zap.Logger) error, logger *zap.Logger) error {
+	_, err := dynamoSession.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
 	if err != nil {
 		var resourceNotFoundException *dynamodb.ResourceNotFoundException
 		if errors.As(err, &resourceNotFoundException) {
-			logger.Warn("Table doesn't exist. Creating...")
-			createTableErr := createTable(dynamoSession, logger)
-			if createTableErr != nil {
-				return nil, createTableErr
-			}
-			return dynamoSession, nil
+			logger.Warn("Table doesn't exist. Creating...", zap.String("table", name))
+			return createFunc(ctx, dynamoSession, logger)
 		}
+		return err
+	}
+	return nil
+}
+
+// InitializeDynamoSession creates a DynamoDB session
+func InitializeDynamoSession(ctx context.Context, logger *zap.Logger) (*dynamodb.DynamoDB, error) {
+	dynamoSession := createDynamoSession()
+	if err := ensureTable(ctx, dynamoSession, tableName, createTable, logger); err != nil {
+		return nil, err
+	}
+	if err := ensureTable(ctx, dynamoSession, commentsTableName, createCommentsTable, logger); err != nil {
+		return nil, err
+	}
+	if err := ensureTable(ctx, dynamoSession, engagementTableName, createEngagementTable, logger); err != nil {
+		return nil, err
+	}
+	if err := ensureTable(ctx, dynamoSession, jobStateTableName, createJobStateTable, logger); err != nil {
+		return nil, err
+	}
+	if err := ensureTable(ctx, dynamoSession, webhookDeliveriesTableName, createWebhookDeliveriesTable, logger); err != nil {
+		return nil, err
+	}
+	if err := migrateParsedIndex(ctx, dynamoSession, logger); err != nil {
+		logger.Error("Failed moving queued posts over to C-3PO deliveries", zap.Error(err))
+		return nil, err
+	}
+	if err := migrateCreatedTimeIndex(ctx, dynamoSession, logger); err != nil {
+		logger.Error("Failed adding the created_time index to the feed table", zap.Error(err))
 		return nil, err
 	}
 	return dynamoSession, nil
 }
 
+// migrateParsedIndex queues the posts left in the parsed_index GSI of feed tables created before C-3PO deliveries
+// were tracked along with the webhook deliveries. Their dispatch state is removed afterwards, which takes them out of
+// the index.
+func migrateParsedIndex(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	describeTableOutput, err := dynamoSession.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return err
+	}
+	hasParsedIndex := false
+	for _, index := range describeTableOutput.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) == parsedGsiIndexName {
+			hasParsedIndex = true
+		}
+	}
+	if !hasParsedIndex {
+		return nil
+	}
+
+	migrated := 0
+	for _, indexValue := range []string{"false", deadLetterValue} {
+		// Collect first, since migrating a post takes it out of the index
+		var items []map[string]*dynamodb.AttributeValue
+		queryInput := dynamodb.QueryInput{
+			ExpressionAttributeNames:  map[string]*string{"#I": &parsedGsiPartitionKey},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":I": {S: aws.String(indexValue)}},
+			IndexName:                 aws.String(parsedGsiIndexName),
+			KeyConditionExpression:    aws.String("#I = :I"),
+			TableName:                 aws.String(tableName),
+		}
+		err := dynamoSession.QueryPagesWithContext(ctx, &queryInput, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+			items = append(items, output.Items...)
+			return !lastPage
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			var legacyState legacyDispatchState
+			if err := unmarshalMapWithEmptyCollections(item, &legacyState); err != nil {
+				return err
+			}
+			marshalledDelivery, err := dynamodbattribute.MarshalMap(legacyState.c3poDelivery())
+			if err != nil {
+				return err
+			}
+			_, err = dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+				Item:      marshalledDelivery,
+				TableName: aws.String(webhookDeliveriesTableName),
+			})
+			if err != nil {
+				return err
+			}
+			_, err = dynamoSession.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+				ExpressionAttributeNames: map[string]*string{
+					"#A": &dispatchAttemptsKey,
+					"#E": &lastDispatchErrorKey,
+					"#I": &parsedGsiPartitionKey,
+					"#N": &nextDispatchTimeKey,
+				},
+				Key:              map[string]*dynamodb.AttributeValue{partitionKey: item[partitionKey], sortKey: item[sortKey]},
+				TableName:        aws.String(tableName),
+				UpdateExpression: aws.String("REMOVE #I, #A, #E, #N"),
+			})
+			if err != nil {
+				return err
+			}
+			migrated++
+		}
+	}
+	logger.Info("Moved queued posts over to C-3PO deliveries", zap.Int("count", migrated))
+	return nil
+}
+
+// migrateCreatedTimeIndex adds the created_time GSI to feed tables created before posts were listed from it. Posts
+// get the partition of the index first, so that an interrupted migration is resumed on the next start.
+func migrateCreatedTimeIndex(ctx context.Context, dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+	describeTableOutput, err := dynamoSession.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return err
+	}
+	for _, index := range describeTableOutput.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) == createdTimeGsiIndexName {
+			return nil
+		}
+	}
+
+	logger.Warn("Feed table has no created_time index. Creating...")
+	var keys []map[string]*dynamodb.AttributeValue
+	scanInput := dynamodb.ScanInput{
+		ExpressionAttributeNames: map[string]*string{
+			"#C": &sortKey,
+			"#F": &partitionKey,
+			"#G": &createdTimeGsiPartitionKey,
+		},
+		FilterExpression:     aws.String("attribute_not_exists(#G)"),
+		ProjectionExpression: aws.String("#F, #C"),
+		TableName:            aws.String(tableName),
+	}
+	err = dynamoSession.ScanPagesWithContext(ctx, &scanInput, func(output *dynamodb.ScanOutput, lastPage bool) bool {
+		keys = append(keys, output.Items...)
+		return !lastPage
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		_, err := dynamoSession.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			ExpressionAttributeNames:  map[string]*string{"#G": &createdTimeGsiPartitionKey},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":G": {S: aws.String(createdTimeGsiPartitionValue)}},
+			Key:                       key,
+			TableName:                 aws.String(tableName),
+			UpdateExpression:          aws.String("SET #G = :G"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = dynamoSession.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(createdTimeGsiPartitionKey),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(sortKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+			{Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+				IndexName:             createdTimeIndex().IndexName,
+				KeySchema:             createdTimeIndex().KeySchema,
+				Projection:            createdTimeIndex().Projection,
+				ProvisionedThroughput: createdTimeIndex().ProvisionedThroughput,
+			}},
+		},
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("Created the created_time index", zap.Int("postsUpdated", len(keys)))
+	return waitForIndex(ctx, dynamoSession, tableName, createdTimeGsiIndexName)
+}
+
+// waitForIndex returns once a GSI being created can be queried
+func waitForIndex(ctx context.Context, dynamoSession *dynamodb.DynamoDB, table string, indexName string) error {
+	for {
+		describeTableOutput, err := dynamoSession.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return err
+		}
+		for _, index := range describeTableOutput.Table.GlobalSecondaryIndexes {
+			if aws.StringValue(index.IndexName) == indexName && aws.StringValue(index.IndexStatus) == dynamodb.IndexStatusActive {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
 func marshalMapWithEmptyCollections(in interface{}) (map[string]*dynamodb.AttributeValue, error) {
 	dynamoEncoder := dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
 		e.EnableEmptyCollections = true
@@ -141,12 +541,30 @@ func unmarshalMapWithEmptyCollections(m map[string]*dynamodb.AttributeValue, out
 	return dynamoDecoder.Decode(&dynamodb.AttributeValue{M: m}, out)
 }
 
-// UpdateOrInsertPost updates a post by ID, or creates it if it doesn't already exist.
-func UpdateOrInsertPost(dynamoSession *dynamodb.DynamoDB, postData PostData, logger *zap.Logger) {
+// DynamoPostStore is a PostStore backed by a DynamoDB table
+type DynamoPostStore struct {
+	dynamoSession *dynamodb.DynamoDB
+	logger        *zap.Logger
+}
+
+// NewDynamoPostStore creates a PostStore using the given DynamoDB session
+func NewDynamoPostStore(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) *DynamoPostStore {
+	return &DynamoPostStore{dynamoSession: dynamoSession, logger: logger}
+}
+
+// UpdateOrInsertPost creates a post, or overwrites it if it was edited since it was stored.
+// Posts stored before updated_time was tracked count as edited once.
+func (s *DynamoPostStore) UpdateOrInsertPost(ctx context.Context, postData PostData) error {
 	// Using a custom marshal method since comments & reaction summary have an empty object value
 	marshalledPostData, err := marshalMapWithEmptyCollections(postData.FacebookPost)
 	if err != nil {
-		logger.Error("Unable to marshal Facebook post", zap.Error(err))
+		s.logger.Error("Unable to marshal Facebook post", zap.Error(err))
+		return err
+	}
+	marshalledLinks, err := dynamodbattribute.Marshal(postData.Links)
+	if err != nil {
+		s.logger.Error("Unable to marshal music links", zap.Error(err))
+		return err
 	}
 
 	createdTime := postData.CreatedTime.Format(time.RFC3339)
@@ -155,47 +573,544 @@ func UpdateOrInsertPost(dynamoSession *dynamodb.DynamoDB, postData PostData, log
 		sortKey:      {S: &createdTime},
 	}
 	expressionAttributeNames := map[string]*string{
-		"#I": &parsedGsiPartitionKey,
+		"#G": &createdTimeGsiPartitionKey,
+		"#L": aws.String("links"),
 		"#P": aws.String("post"),
+		"#U": aws.String("updated_time"),
 	}
 	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
-		":I": {S: aws.String("false")},
+		":G": {S: aws.String(createdTimeGsiPartitionValue)},
+		":L": marshalledLinks,
 		":P": {M: marshalledPostData},
+		":U": {S: aws.String(postData.UpdatedTime.UTC().Format(time.RFC3339))},
 	}
 	updateItemInput := dynamodb.UpdateItemInput{
+		ConditionExpression:       aws.String("attribute_not_exists(#P) OR attribute_not_exists(#U) OR #U < :U"),
 		ExpressionAttributeNames:  expressionAttributeNames,
 		ExpressionAttributeValues: expressionAttributeValues,
 		Key:                       key,
 		TableName:                 &tableName,
-		UpdateExpression:          aws.String("SET #P = if_not_exists(#P, :P), #I = :I"),
+		UpdateExpression:          aws.String("SET #P = :P, #U = :U, #L = :L, #G = :G"),
 	}
-	_, err = dynamoSession.UpdateItem(&updateItemInput)
+	_, err = s.dynamoSession.UpdateItemWithContext(ctx, &updateItemInput)
 	if err != nil {
-		logger.Error("Failed to UpdateOrInsertPost", zap.String("FacebookId", postData.FacebookID), zap.Error(err))
+		var conditionalCheckFailedException *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailedException) {
+			s.logger.Debug("Post unchanged, skipping update", zap.String("FacebookID", postData.FacebookID))
+			return nil
+		}
+		s.logger.Error("Failed to UpdateOrInsertPost", zap.String("FacebookId", postData.FacebookID), zap.Error(err))
+		return err
 	}
-	logger.Info("UpdateOrInsertPost success", zap.String("FacebookID", postData.FacebookID))
+	s.logger.Info("UpdateOrInsertPost success", zap.String("FacebookID", postData.FacebookID))
+	return nil
 }
 
-// MarkPostAsParsed marks a post in DB as parsed by C-3PO
-func MarkPostAsParsed(dynamoSession *dynamodb.DynamoDB, postData PostData, logger *zap.Logger) bool {
+// MarkPostAsDeleted sets the tombstone field of a post
+func (s *DynamoPostStore) MarkPostAsDeleted(ctx context.Context, postData PostData) error {
 	key := map[string]*dynamodb.AttributeValue{
 		partitionKey: {S: &postData.FacebookID},
 		sortKey:      {S: aws.String(postData.CreatedTime.Format(time.RFC3339))},
 	}
-	expressionAttributeNames := map[string]*string{
-		"#I": &parsedGsiPartitionKey,
+	updateItemInput := dynamodb.UpdateItemInput{
+		ExpressionAttributeNames: map[string]*string{"#D": aws.String("deleted_time")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":D": {S: aws.String(postData.DeletedTime.UTC().Format(time.RFC3339))},
+		},
+		Key:              key,
+		TableName:        &tableName,
+		UpdateExpression: aws.String("SET #D = :D"),
+	}
+	_, err := s.dynamoSession.UpdateItemWithContext(ctx, &updateItemInput)
+	if err != nil {
+		s.logger.Warn("MarkPostAsDeleted failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RecordDispatch appends a dispatch attempt to the post's dispatch_history list. Once the list holds
+// maxDispatchHistory attempts, it's rewritten without the oldest ones instead.
+func (s *DynamoPostStore) RecordDispatch(ctx context.Context, postData PostData, record DispatchRecord) error {
+	marshalledRecord, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		s.logger.Error("Unable to marshal dispatch record", zap.Error(err))
+		return err
 	}
 
+	key := map[string]*dynamodb.AttributeValue{
+		partitionKey: {S: &postData.FacebookID},
+		sortKey:      {S: aws.String(postData.CreatedTime.Format(time.RFC3339))},
+	}
 	updateItemInput := dynamodb.UpdateItemInput{
-		ExpressionAttributeNames: expressionAttributeNames,
+		ConditionExpression:      aws.String("attribute_not_exists(#D) OR size(#D) < :max"),
+		ExpressionAttributeNames: map[string]*string{"#D": &dispatchHistoryKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":D":     {L: []*dynamodb.AttributeValue{{M: marshalledRecord}}},
+			":empty": {L: []*dynamodb.AttributeValue{}},
+			":max":   {N: aws.String(strconv.Itoa(maxDispatchHistory))},
+		},
+		Key:              key,
+		TableName:        &tableName,
+		UpdateExpression: aws.String("SET #D = list_append(if_not_exists(#D, :empty), :D)"),
+	}
+	_, err = s.dynamoSession.UpdateItemWithContext(ctx, &updateItemInput)
+
+	// A full history is rewritten, as long as no other attempt was recorded in the meantime
+	var conditionalCheckFailedException *dynamodb.ConditionalCheckFailedException
+	for tries := 0; tries < 3 && errors.As(err, &conditionalCheckFailedException); tries++ {
+		err = s.rewriteDispatchHistory(ctx, key, marshalledRecord)
+	}
+	if err != nil {
+		s.logger.Warn("RecordDispatch failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// rewriteDispatchHistory replaces the dispatch history of a post with its latest attempts followed by a new one,
+// failing with a ConditionalCheckFailedException if the history changed since it was read
+func (s *DynamoPostStore) rewriteDispatchHistory(ctx context.Context, key map[string]*dynamodb.AttributeValue, marshalledRecord map[string]*dynamodb.AttributeValue) error {
+	names := map[string]*string{"#D": &dispatchHistoryKey}
+	getItemOutput, err := s.dynamoSession.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		ConsistentRead:           aws.Bool(true),
+		ExpressionAttributeNames: names,
 		Key:                      key,
+		ProjectionExpression:     aws.String("#D"),
 		TableName:                &tableName,
-		UpdateExpression:         aws.String("REMOVE #I"),
+	})
+	if err != nil {
+		return err
+	}
+
+	values := map[string]*dynamodb.AttributeValue{}
+	condition := "attribute_not_exists(#D)"
+	var latest []*dynamodb.AttributeValue
+	if history, ok := getItemOutput.Item[dispatchHistoryKey]; ok {
+		values[":read"] = history
+		condition = "#D = :read"
+		latest = history.L
+		if len(latest) >= maxDispatchHistory {
+			latest = latest[len(latest)-maxDispatchHistory+1:]
+		}
+	}
+	rewritten := make([]*dynamodb.AttributeValue, 0, len(latest)+1)
+	values[":D"] = &dynamodb.AttributeValue{L: append(append(rewritten, latest...), &dynamodb.AttributeValue{M: marshalledRecord})}
+	_, err = s.dynamoSession.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		Key:                       key,
+		TableName:                 &tableName,
+		UpdateExpression:          aws.String("SET #D = :D"),
+	})
+	return err
+}
+
+// GetPost fetches a post by its Facebook ID
+func (s *DynamoPostStore) GetPost(ctx context.Context, facebookID string) (PostData, error) {
+	var postData PostData
+	queryOutput, err := s.dynamoSession.QueryWithContext(ctx, &dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#F": &partitionKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":F": {S: aws.String(facebookID)}},
+		KeyConditionExpression:    aws.String("#F = :F"),
+		Limit:                     aws.Int64(1),
+		TableName:                 aws.String(tableName),
+	})
+	if err != nil {
+		s.logger.Warn("GetPost failed", zap.String("FacebookID", facebookID), zap.Error(err))
+		return postData, err
+	}
+	if len(queryOutput.Items) == 0 {
+		return postData, ErrPostNotFound
+	}
+	err = unmarshalMapWithEmptyCollections(queryOutput.Items[0], &postData)
+	return postData, err
+}
+
+// createdTimeCondition builds the created_time range condition of a query, if any
+func createdTimeCondition(query PostQuery, names map[string]*string, values map[string]*dynamodb.AttributeValue) string {
+	hasAfter, hasBefore := !query.CreatedAfter.IsZero(), !query.CreatedBefore.IsZero()
+	if hasAfter {
+		values[":after"] = &dynamodb.AttributeValue{S: aws.String(query.CreatedAfter.UTC().Format(time.RFC3339))}
+	}
+	if hasBefore {
+		values[":before"] = &dynamodb.AttributeValue{S: aws.String(query.CreatedBefore.UTC().Format(time.RFC3339))}
+	}
+	if hasAfter || hasBefore {
+		names["#C"] = &sortKey
+	}
+	switch {
+	case hasAfter && hasBefore:
+		return "#C BETWEEN :after AND :before"
+	case hasAfter:
+		return "#C >= :after"
+	case hasBefore:
+		return "#C <= :before"
+	}
+	return ""
+}
+
+// ListPosts returns a page of posts matching the query, newest first, from the created_time index. Posts are
+// filtered on the status of their C-3PO delivery, read beforehand from the status index of the deliveries.
+func (s *DynamoPostStore) ListPosts(ctx context.Context, query PostQuery) (PostPage, error) {
+	cursorKey, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return PostPage{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPostPageLimit
+	}
+
+	var c3poStatuses map[string]string
+	if filtersC3poStatus(query) {
+		c3poStatuses, err = s.listC3poStatuses(ctx)
+		if err != nil {
+			s.logger.Warn("ListPosts failed reading C-3PO deliveries", zap.Error(err))
+			return PostPage{}, err
+		}
+		if !anyC3poStatusMatches(c3poStatuses, query) {
+			return PostPage{}, nil
+		}
+	}
+
+	names := map[string]*string{"#G": &createdTimeGsiPartitionKey}
+	values := map[string]*dynamodb.AttributeValue{":G": {S: aws.String(createdTimeGsiPartitionValue)}}
+	keyCondition := "#G = :G"
+	if createdTime := createdTimeCondition(query, names, values); createdTime != "" {
+		keyCondition += " AND " + createdTime
+	}
+
+	var exclusiveStartKey map[string]*dynamodb.AttributeValue
+	if cursorKey != nil {
+		exclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			createdTimeGsiPartitionKey: {S: aws.String(createdTimeGsiPartitionValue)},
+			partitionKey:               {S: aws.String(cursorKey[partitionKey])},
+			sortKey:                    {S: aws.String(cursorKey[sortKey])},
+		}
+	}
+
+	// Read pages until the limit is met, since filters may drop items from a page
+	var page PostPage
+	for {
+		queryOutput, err := s.dynamoSession.QueryWithContext(ctx, &dynamodb.QueryInput{
+			ExclusiveStartKey:         exclusiveStartKey,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+			IndexName:                 aws.String(createdTimeGsiIndexName),
+			KeyConditionExpression:    aws.String(keyCondition),
+			Limit:                     aws.Int64(int64(query.Limit - len(page.Posts))),
+			ScanIndexForward:          aws.Bool(false),
+			TableName:                 aws.String(tableName),
+		})
+		if err != nil {
+			s.logger.Warn("ListPosts query failed", zap.Error(err))
+			return PostPage{}, err
+		}
+
+		for _, item := range queryOutput.Items {
+			var postData PostData
+			if err := unmarshalMapWithEmptyCollections(item, &postData); err != nil {
+				s.logger.Error("Failed to unmarshal post from DB", zap.Any("entry", item), zap.Error(err))
+				continue
+			}
+			if !matchesC3poStatus(c3poStatuses[postData.FacebookID], query) {
+				continue
+			}
+			page.Posts = append(page.Posts, postData)
+		}
+
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if len(exclusiveStartKey) == 0 || len(page.Posts) >= query.Limit {
+			break
+		}
+	}
+
+	if len(exclusiveStartKey) > 0 {
+		page.NextCursor = encodeCursor(map[string]string{
+			partitionKey: aws.StringValue(exclusiveStartKey[partitionKey].S),
+			sortKey:      aws.StringValue(exclusiveStartKey[sortKey].S),
+		})
+	}
+	return page, nil
+}
+
+// listC3poStatuses returns the status of the C-3PO deliveries that are pending or failed, by Facebook ID. Posts
+// missing from it have their events delivered, or no delivery at all.
+func (s *DynamoPostStore) listC3poStatuses(ctx context.Context) (map[string]string, error) {
+	c3poStatuses := map[string]string{}
+	for _, status := range []string{webhookDeliveryPending, webhookDeliveryFailed} {
+		err := s.QueryWebhookDeliveries(ctx, status, func(delivery WebhookDelivery) bool {
+			if delivery.Subscriber == c3poSubscriberName {
+				c3poStatuses[delivery.FacebookID] = delivery.Status
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c3poStatuses, nil
+}
+
+// anyC3poStatusMatches reports whether any post may pass the C-3PO status filters of a query, given the statuses
+// returned by listC3poStatuses
+func anyC3poStatusMatches(c3poStatuses map[string]string, query PostQuery) bool {
+	if matchesC3poStatus("", query) {
+		return true
+	}
+	for _, status := range c3poStatuses {
+		if matchesC3poStatus(status, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateOrInsertComment stores a comment of a post, overwriting any previous version
+func (s *DynamoPostStore) UpdateOrInsertComment(ctx context.Context, comment CommentData) error {
+	// Comments have empty objects too, e.g. message_tags
+	item, err := marshalMapWithEmptyCollections(comment)
+	if err != nil {
+		s.logger.Error("Unable to marshal comment", zap.Error(err))
+		return err
+	}
+	_, err = s.dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(commentsTableName),
+	})
+	if err != nil {
+		s.logger.Warn("UpdateOrInsertComment failed", zap.String("FacebookID", comment.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteComment removes a stored comment of a post, if any
+func (s *DynamoPostStore) DeleteComment(ctx context.Context, postID string, commentID string) error {
+	_, err := s.dynamoSession.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			commentsPartitionKey: {S: aws.String(postID)},
+			partitionKey:         {S: aws.String(commentID)},
+		},
+		TableName: aws.String(commentsTableName),
+	})
+	if err != nil {
+		s.logger.Warn("DeleteComment failed", zap.String("FacebookID", commentID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListComments returns the comments of a post, replies included, oldest first
+func (s *DynamoPostStore) ListComments(ctx context.Context, postID string) ([]CommentData, error) {
+	comments := []CommentData{}
+	queryInput := dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#P": &commentsPartitionKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":P": {S: aws.String(postID)}},
+		KeyConditionExpression:    aws.String("#P = :P"),
+		TableName:                 aws.String(commentsTableName),
+	}
+	err := s.dynamoSession.QueryPagesWithContext(ctx, &queryInput, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, entry := range output.Items {
+			var comment CommentData
+			if err := unmarshalMapWithEmptyCollections(entry, &comment); err != nil {
+				s.logger.Error("Failed to unmarshal comment from DB", zap.Any("entry", entry), zap.Error(err))
+				continue
+			}
+			comments = append(comments, comment)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		s.logger.Warn("ListComments failed", zap.String("PostID", postID), zap.Error(err))
+		return nil, err
+	}
+	sortComments(comments)
+	return comments, nil
+}
+
+// RecordEngagementSnapshot stores a sample of the reaction and comment totals of a post
+func (s *DynamoPostStore) RecordEngagementSnapshot(ctx context.Context, snapshot EngagementSnapshot) error {
+	item, err := dynamodbattribute.MarshalMap(snapshot)
+	if err != nil {
+		s.logger.Error("Unable to marshal engagement snapshot", zap.Error(err))
+		return err
+	}
+	_, err = s.dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(engagementTableName),
+	})
+	if err != nil {
+		s.logger.Warn("RecordEngagementSnapshot failed", zap.String("FacebookID", snapshot.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListEngagementSnapshots returns the engagement samples of a post, oldest first
+func (s *DynamoPostStore) ListEngagementSnapshots(ctx context.Context, facebookID string) ([]EngagementSnapshot, error) {
+	snapshots := []EngagementSnapshot{}
+	queryInput := dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#F": &partitionKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":F": {S: aws.String(facebookID)}},
+		KeyConditionExpression:    aws.String("#F = :F"),
+		ScanIndexForward:          aws.Bool(true),
+		TableName:                 aws.String(engagementTableName),
+	}
+	var unmarshalErr error
+	err := s.dynamoSession.QueryPagesWithContext(ctx, &queryInput, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+		var page []EngagementSnapshot
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(output.Items, &page); unmarshalErr != nil {
+			return false
+		}
+		snapshots = append(snapshots, page...)
+		return !lastPage
+	})
+	if err == nil {
+		err = unmarshalErr
+	}
+	if err != nil {
+		s.logger.Warn("ListEngagementSnapshots failed", zap.String("FacebookID", facebookID), zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetBackfillState returns the progress of the last backfill
+func (s *DynamoPostStore) GetBackfillState(ctx context.Context) (BackfillState, error) {
+	var state BackfillState
+	getItemOutput, err := s.dynamoSession.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{jobStatePartitionKey: {S: &backfillJobName}},
+		TableName: aws.String(jobStateTableName),
+	})
+	if err != nil {
+		s.logger.Warn("GetBackfillState failed", zap.Error(err))
+		return state, err
+	}
+	if getItemOutput.Item == nil {
+		return state, ErrBackfillNotFound
+	}
+	err = dynamodbattribute.UnmarshalMap(getItemOutput.Item, &state)
+	return state, err
+}
+
+// SaveBackfillState persists the progress of the running backfill
+func (s *DynamoPostStore) SaveBackfillState(ctx context.Context, state BackfillState) error {
+	item, err := dynamodbattribute.MarshalMap(state)
+	if err != nil {
+		s.logger.Error("Unable to marshal backfill state", zap.Error(err))
+		return err
 	}
-	_, err := dynamoSession.UpdateItem(&updateItemInput)
+	item[jobStatePartitionKey] = &dynamodb.AttributeValue{S: &backfillJobName}
+	_, err = s.dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(jobStateTableName),
+	})
 	if err != nil {
-		logger.Warn("MarkPostAsParsed failed", zap.Error(err))
-		return false
+		s.logger.Warn("SaveBackfillState failed", zap.Error(err))
+		return err
 	}
-	return true
+	return nil
+}
+
+// UpdateOrInsertWebhookDelivery stores the delivery state of a post for a subscriber
+func (s *DynamoPostStore) UpdateOrInsertWebhookDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	item, err := dynamodbattribute.MarshalMap(delivery)
+	if err != nil {
+		s.logger.Error("Unable to marshal webhook delivery", zap.Error(err))
+		return err
+	}
+	_, err = s.dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(webhookDeliveriesTableName),
+	})
+	if err != nil {
+		s.logger.Warn("UpdateOrInsertWebhookDelivery failed", zap.String("FacebookID", delivery.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateWebhookDelivery stores the delivery state of a post for a subscriber, on the condition that its updated_at is
+// still the one read
+func (s *DynamoPostStore) UpdateWebhookDelivery(ctx context.Context, delivery WebhookDelivery, readUpdatedAt time.Time) error {
+	item, err := dynamodbattribute.MarshalMap(delivery)
+	if err != nil {
+		s.logger.Error("Unable to marshal webhook delivery", zap.Error(err))
+		return err
+	}
+	marshalledUpdatedAt, err := dynamodbattribute.Marshal(readUpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = s.dynamoSession.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		ConditionExpression:       aws.String("attribute_not_exists(#U) OR #U = :U"),
+		ExpressionAttributeNames:  map[string]*string{"#U": &webhookStatusGsiSortKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":U": marshalledUpdatedAt},
+		Item:                      item,
+		TableName:                 aws.String(webhookDeliveriesTableName),
+	})
+	if err != nil {
+		var conditionalCheckFailedException *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailedException) {
+			return ErrWebhookDeliveryChanged
+		}
+		s.logger.Warn("UpdateWebhookDelivery failed", zap.String("FacebookID", delivery.FacebookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the delivery state of a post for every subscriber
+func (s *DynamoPostStore) ListWebhookDeliveries(ctx context.Context, facebookID string) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	queryInput := dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#F": &partitionKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":F": {S: aws.String(facebookID)}},
+		KeyConditionExpression:    aws.String("#F = :F"),
+		TableName:                 aws.String(webhookDeliveriesTableName),
+	}
+	var unmarshalErr error
+	err := s.dynamoSession.QueryPagesWithContext(ctx, &queryInput, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+		var page []WebhookDelivery
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(output.Items, &page); unmarshalErr != nil {
+			return false
+		}
+		deliveries = append(deliveries, page...)
+		return !lastPage
+	})
+	if err == nil {
+		err = unmarshalErr
+	}
+	if err != nil {
+		s.logger.Warn("ListWebhookDeliveries failed", zap.String("FacebookID", facebookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// QueryWebhookDeliveries pages through the status index, oldest deliveries first
+func (s *DynamoPostStore) QueryWebhookDeliveries(ctx context.Context, status string, fn func(delivery WebhookDelivery) bool) error {
+	queryInput := dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#S": &webhookStatusGsiPartitionKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":S": {S: aws.String(status)}},
+		IndexName:                 aws.String(webhookStatusGsiIndexName),
+		KeyConditionExpression:    aws.String("#S = :S"),
+		ScanIndexForward:          aws.Bool(true),
+		TableName:                 aws.String(webhookDeliveriesTableName),
+	}
+	return s.dynamoSession.QueryPagesWithContext(ctx, &queryInput, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, entry := range output.Items {
+			var delivery WebhookDelivery
+			if err := dynamodbattribute.UnmarshalMap(entry, &delivery); err != nil {
+				s.logger.Error("Failed to unmarshal webhook delivery from DB", zap.Any("entry", entry), zap.Error(err))
+				continue
+			}
+			if !fn(delivery) {
+				return false
+			}
+		}
+		return !lastPage
+	})
 }
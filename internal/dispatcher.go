@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// errMissingC3poCredentials is returned by runs of the dispatcher without C-3PO credentials to send
+var errMissingC3poCredentials = errors.New("C-3PO credentials `C3PO_SIGNING_SECRET` or `WHOAMI` not present")
+
+// Dispatcher sends the pending C-3PO deliveries from a bounded pool of workers.
+// The rate limit and concurrency towards C-3PO are shared by all runs of the dispatcher and by the deliveries
+// dispatched right away from the API, so a process creates a single one.
+type Dispatcher struct {
+	store       PostStore
+	logger      *zap.Logger
+	concurrency int
+	batchSize   int
+	limiter     *rate.Limiter
+	// slots holds a token for every request to C-3PO in flight
+	slots chan struct{}
+
+	mu      sync.Mutex
+	running bool
+	// batchUnsupported is set once C-3PO rejected the batch endpoint, falling back to single posts from then on
+	batchUnsupported bool
+}
+
+// NewDispatcher creates a Dispatcher configured by the `DISPATCHER_CONCURRENCY`, `DISPATCHER_RATE_LIMIT`
+// and `DISPATCHER_BATCH_SIZE` settings
+func NewDispatcher(store PostStore, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		logger:      logger,
+		concurrency: config.DispatcherConcurrency,
+		batchSize:   config.DispatcherBatchSize,
+		limiter:     rate.NewLimiter(rate.Limit(config.DispatcherRateLimit), config.DispatcherConcurrency),
+		slots:       make(chan struct{}, config.DispatcherConcurrency),
+	}
+}
+
+// DispatchNow sends a single C-3PO delivery without waiting for the next run, once a request slot and the rate limit
+// allow it
+func (d *Dispatcher) DispatchNow(ctx context.Context, delivery WebhookDelivery) error {
+	if config.Whoami == "" && config.C3POSigningSecret == "" {
+		return errMissingC3poCredentials
+	}
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return dispatchItem(ctx, d.store, delivery, d.logger)
+}
+
+// acquire waits for a free request slot, then for the rate limit, returning the function freeing the slot
+func (d *Dispatcher) acquire(ctx context.Context) (func(), error) {
+	select {
+	case d.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := d.limiter.Wait(ctx); err != nil {
+		<-d.slots
+		return nil, err
+	}
+	return func() { <-d.slots }, nil
+}
+
+// Run dispatches every pending C-3PO delivery, returning once all of them were attempted or ctx is cancelled.
+// Cancelling ctx also cancels the requests to C-3PO in flight, leaving their deliveries pending for the next run.
+// A call made while another run is in progress returns right away, since the running one already picks up every
+// pending delivery.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	if config.Whoami == "" && config.C3POSigningSecret == "" {
+		return errMissingC3poCredentials
+	}
+
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		d.logger.Info("Dispatcher already running, skipping run")
+		return nil
+	}
+	d.running = true
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.running = false
+		d.mu.Unlock()
+	}()
+
+	ctx, span := startSpan(ctx, "DispatchFreshPosts")
+	err := d.run(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// run dispatches every pending C-3PO delivery on behalf of Run
+func (d *Dispatcher) run(ctx context.Context) error {
+	// Start the workers
+	batches := make(chan []WebhookDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < d.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				d.dispatch(ctx, batch)
+			}
+		}()
+	}
+
+	// Dispatch all pending deliveries to C-3PO, unless they're backing off from a failure
+	now := time.Now()
+	var batch []WebhookDelivery
+	sendBatch := func() bool {
+		select {
+		case batches <- batch:
+			batch = nil
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	queued := 0
+	err := d.store.QueryWebhookDeliveries(ctx, webhookDeliveryPending, func(delivery WebhookDelivery) bool {
+		if delivery.Subscriber != c3poSubscriberName {
+			return true
+		}
+		queued++
+		if !isDeliveryDue(delivery, now) {
+			return true
+		}
+		batch = append(batch, delivery)
+		if len(batch) < d.batchSize {
+			return true
+		}
+		return sendBatch()
+	})
+	if err == nil && len(batch) > 0 {
+		sendBatch()
+	}
+	close(batches)
+	wg.Wait()
+
+	if err != nil {
+		d.logger.Warn("Failed querying pending deliveries for dispatching", zap.Error(err))
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// A cancelled run stops counting early, so the backlog is only known once a run completes
+	unparsedPosts.Set(float64(queued))
+	trace.SpanFromContext(ctx).SetAttributes(label.Int("queued", queued))
+	return nil
+}
+
+// dispatch sends a batch of deliveries to C-3PO, posts in a single request unless batching is disabled or
+// unsupported. Deletions are always sent one by one.
+func (d *Dispatcher) dispatch(ctx context.Context, batch []WebhookDelivery) {
+	var singles, posts []WebhookDelivery
+	for _, delivery := range batch {
+		if delivery.Event == webhookEventDeleted {
+			singles = append(singles, delivery)
+		} else {
+			posts = append(posts, delivery)
+		}
+	}
+
+	if len(posts) > 1 && !d.isBatchUnsupported() {
+		release, err := d.acquire(ctx)
+		if err != nil {
+			return
+		}
+		err = dispatchBatch(ctx, d.store, posts, d.logger)
+		release()
+		if errors.Is(err, errC3poEndpointUnsupported) {
+			d.logger.Warn("C-3PO doesn't support batches, falling back to single posts")
+			d.mu.Lock()
+			d.batchUnsupported = true
+			d.mu.Unlock()
+		} else {
+			if err != nil {
+				d.logger.Warn("Dispatching batch to C-3PO failed", zap.Error(err))
+			}
+			posts = nil
+		}
+	}
+
+	for _, delivery := range append(singles, posts...) {
+		release, err := d.acquire(ctx)
+		if err != nil {
+			return
+		}
+		err = dispatchItem(ctx, d.store, delivery, d.logger)
+		release()
+		if err != nil {
+			d.logger.Warn("Dispatching post to C-3PO failed", zap.Error(err))
+		}
+	}
+}
+
+// isBatchUnsupported reports whether C-3PO rejected the batch endpoint before
+func (d *Dispatcher) isBatchUnsupported() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.batchUnsupported
+}
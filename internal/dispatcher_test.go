@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+func TestDispatcherRun(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(C3poResponse{Success: true})
+	}))
+	t.Cleanup(server.Close)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = server.URL
+		c.DispatcherConcurrency = 3
+		c.DispatcherRateLimit = 1000
+		c.Whoami = "test"
+	})
+
+	store := NewMemoryPostStore()
+	for i := 0; i < 20; i++ {
+		queueTestPost(t, store, PostData{
+			CreatedTime:  time.Date(2020, 10, 1, i, 0, 0, 0, time.UTC),
+			FacebookID:   fmt.Sprintf("1_%d", i),
+			FacebookPost: fb.Result{},
+		})
+	}
+	dispatcher := NewDispatcher(store, zap.NewNop())
+
+	// A cancelled run doesn't dispatch anything
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dispatcher.Run(ctx); err != context.Canceled {
+		t.Errorf("Expected cancelled run to return context.Canceled, got %v", err)
+	}
+
+	if err := dispatcher.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pendingCount := 0
+	_ = store.QueryWebhookDeliveries(context.Background(), webhookDeliveryPending, func(WebhookDelivery) bool {
+		pendingCount++
+		return true
+	})
+	if pendingCount != 0 {
+		t.Errorf("Expected every post to be dispatched, %d left", pendingCount)
+	}
+	if maxInFlight > 3 || maxInFlight < 2 {
+		t.Errorf("Expected up to 3 concurrent requests to C-3PO, got %d", maxInFlight)
+	}
+}
+
+func TestDispatcherRunBatches(t *testing.T) {
+	for _, batchSupported := range []bool{true, false} {
+		var mu sync.Mutex
+		var batchSizes []int
+		singleRequests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path != "/v1/data/post/batch" {
+				singleRequests++
+				_ = json.NewEncoder(w).Encode(C3poResponse{Success: true})
+				return
+			}
+			if !batchSupported {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var batchRequest C3poBatchRequest
+			_ = json.NewDecoder(r.Body).Decode(&batchRequest)
+			batchSizes = append(batchSizes, len(batchRequest.Posts))
+			response := C3poResponse{Success: true}
+			for _, c3poRequest := range batchRequest.Posts {
+				// C-3PO fails to parse 1_0 and forgets about 1_1
+				switch c3poRequest.FacebookID {
+				case "1_0":
+					response.Results = append(response.Results, C3poItemResponse{FacebookID: c3poRequest.FacebookID, Error: "no song"})
+				case "1_1":
+				default:
+					response.Results = append(response.Results, C3poItemResponse{FacebookID: c3poRequest.FacebookID, Success: true})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		}))
+		setTestConfig(t, func(c *Config) {
+			c.C3POURI = server.URL
+			c.DispatcherConcurrency = 1
+			c.DispatcherRateLimit = 1000
+			c.DispatcherBatchSize = 4
+			c.Whoami = "test"
+		})
+
+		store := NewMemoryPostStore()
+		for i := 0; i < 10; i++ {
+			queueTestPost(t, store, PostData{
+				CreatedTime:  time.Date(2020, 10, 1, i, 0, 0, 0, time.UTC),
+				FacebookID:   fmt.Sprintf("1_%d", i),
+				FacebookPost: fb.Result{},
+			})
+		}
+		if err := NewDispatcher(store, zap.NewNop()).Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		server.Close()
+
+		if batchSupported {
+			if fmt.Sprint(batchSizes) != "[4 4 2]" || singleRequests != 0 {
+				t.Errorf("Expected 10 posts to be sent in batches of 4, got %v and %d single requests", batchSizes, singleRequests)
+			}
+			for facebookID, expectedError := range map[string]string{"1_0": "no song", "1_1": errC3poNoBatchResult.Error(), "1_2": ""} {
+				delivery := c3poDeliveryOf(t, store, facebookID)
+				if delivery.LastError != expectedError || (delivery.Status == webhookDeliveryDelivered) != (expectedError == "") {
+					t.Errorf("Unexpected dispatch outcome for %s: %+v", facebookID, delivery)
+				}
+			}
+		} else if singleRequests != 10 {
+			t.Errorf("Expected a fallback to single posts, got %d single requests", singleRequests)
+		}
+	}
+}
+
+func TestDispatcherSkipsUnreadablePosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batchRequest C3poBatchRequest
+		_ = json.NewDecoder(r.Body).Decode(&batchRequest)
+		response := C3poResponse{Success: true}
+		for _, c3poRequest := range batchRequest.Posts {
+			response.Results = append(response.Results, C3poItemResponse{FacebookID: c3poRequest.FacebookID, Success: true})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	for _, batchSize := range []int{1, 4} {
+		t.Run(fmt.Sprintf("batches of %d", batchSize), func(t *testing.T) {
+			setTestConfig(t, func(c *Config) {
+				c.C3POURI = server.URL
+				c.DispatcherConcurrency = 1
+				c.DispatcherRateLimit = 1000
+				c.DispatcherBatchSize = batchSize
+				c.Whoami = "test"
+			})
+			store := NewMemoryPostStore()
+			for i := 0; i < 3; i++ {
+				queueTestPost(t, store, PostData{
+					CreatedTime:  time.Date(2020, 10, 1, i, 0, 0, 0, time.UTC),
+					FacebookID:   fmt.Sprintf("1_%d", i),
+					FacebookPost: fb.Result{},
+				})
+			}
+			// The event of a post missing from the store
+			queueWebhookEvent(context.Background(), store, "1_9", webhookEventNew, zap.NewNop())
+
+			if err := NewDispatcher(store, zap.NewNop()).Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				if delivery := c3poDeliveryOf(t, store, fmt.Sprintf("1_%d", i)); delivery.Status != webhookDeliveryDelivered {
+					t.Errorf("Expected the readable posts to be dispatched, got %+v", delivery)
+				}
+			}
+			delivery := c3poDeliveryOf(t, store, "1_9")
+			if delivery.Status != webhookDeliveryPending || delivery.Attempts != 1 || delivery.NextAttemptTime == nil {
+				t.Errorf("Expected the unreadable post to back off as a failed attempt, got %+v", delivery)
+			}
+		})
+	}
+}
+
+func TestDispatcherRunWithoutCredentials(t *testing.T) {
+	setTestConfig(t, func(c *Config) {
+		c.Whoami = ""
+		c.C3POSigningSecret = ""
+	})
+	if err := NewDispatcher(NewMemoryPostStore(), zap.NewNop()).Run(context.Background()); err != errMissingC3poCredentials {
+		t.Errorf("Expected the run to fail without C-3PO credentials, got %v", err)
+	}
+}
+
+func TestDispatchNowSharesConcurrency(t *testing.T) {
+	c3po := newTestC3po(t, true)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = c3po.URL
+		c.Whoami = "test"
+		c.DispatcherConcurrency = 1
+	})
+	store := NewMemoryPostStore()
+	queueTestPost(t, store, PostData{CreatedTime: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), FacebookID: "1_1", FacebookPost: fb.Result{}})
+	delivery := c3poDeliveryOf(t, store, "1_1")
+	dispatcher := NewDispatcher(store, zap.NewNop())
+
+	// While a request of a run holds the only slot, posts dispatched right away wait for it
+	release, err := dispatcher.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.DispatchNow(ctx, delivery); err != context.DeadlineExceeded {
+		t.Errorf("Expected the dispatch to wait for a free slot, got %v", err)
+	}
+	release()
+
+	if err := dispatcher.DispatchNow(context.Background(), delivery); err != nil {
+		t.Fatal(err)
+	}
+	if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryDelivered {
+		t.Errorf("Expected the post to be dispatched once the slot is free, got %+v", delivery)
+	}
+}
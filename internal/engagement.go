@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// engagementSnapshotMaxAge is how long after its creation a post keeps being sampled
+func engagementSnapshotMaxAge() time.Duration {
+	return time.Duration(config.EngagementSnapshotMaxAgeDays) * 24 * time.Hour
+}
+
+// newEngagementSnapshot reads the reaction and comment totals from the summaries requested by fbFeedParams
+func newEngagementSnapshot(postData PostData, sampledAt time.Time) (EngagementSnapshot, error) {
+	// Truncated so that sampling times sort lexicographically in the DB
+	snapshot := EngagementSnapshot{FacebookID: postData.FacebookID, SampledAt: sampledAt.UTC().Truncate(time.Second)}
+	if err := postData.FacebookPost.DecodeField("reactions.summary.total_count", &snapshot.Reactions); err != nil {
+		return snapshot, err
+	}
+	if err := postData.FacebookPost.DecodeField("comments.summary.total_count", &snapshot.Comments); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+// recordEngagementSnapshot samples the engagement of a post if it's young enough to be tracked
+func recordEngagementSnapshot(ctx context.Context, store PostStore, postData PostData, sampledAt time.Time, maxAge time.Duration, logger *zap.Logger) {
+	if sampledAt.Sub(postData.CreatedTime) > maxAge {
+		return
+	}
+	snapshot, err := newEngagementSnapshot(postData, sampledAt)
+	if err != nil {
+		logger.Debug("Post has no engagement summary", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+		return
+	}
+	if err := store.RecordEngagementSnapshot(ctx, snapshot); err != nil {
+		logger.Warn("Failed to record engagement snapshot", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+	}
+}
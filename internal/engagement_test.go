@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+func TestRecordEngagementSnapshot(t *testing.T) {
+	store := NewMemoryPostStore()
+	sampledAt := time.Date(2020, 10, 10, 12, 0, 0, 500, time.UTC)
+	post := fb.Result{
+		"reactions": map[string]interface{}{"summary": map[string]interface{}{"total_count": 12}},
+		"comments":  map[string]interface{}{"summary": map[string]interface{}{"total_count": 4}},
+	}
+	young := PostData{FacebookID: "1_1", CreatedTime: sampledAt.AddDate(0, 0, -1), FacebookPost: post}
+	old := PostData{FacebookID: "1_2", CreatedTime: sampledAt.AddDate(0, 0, -30), FacebookPost: post}
+
+	for _, postData := range []PostData{young, old} {
+		recordEngagementSnapshot(context.Background(), store, postData, sampledAt, 7*24*time.Hour, zap.NewNop())
+	}
+
+	snapshots, _ := store.ListEngagementSnapshots(context.Background(), "1_1")
+	if len(snapshots) != 1 || snapshots[0].Reactions != 12 || snapshots[0].Comments != 4 || snapshots[0].SampledAt.Nanosecond() != 0 {
+		t.Errorf("Expected one truncated snapshot of the young post, got %+v", snapshots)
+	}
+	if snapshots, _ := store.ListEngagementSnapshots(context.Background(), "1_2"); len(snapshots) != 0 {
+		t.Errorf("Expected posts older than the max age not to be sampled, got %+v", snapshots)
+	}
+}
@@ -1,22 +1,23 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/cenkalti/backoff/v4"
 	fb "github.com/huandu/facebook/v2"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 const fbGroupID = "1488511748129645"
 
-var whoamiHeaderVal = GetEnv("WHOAMI", "")
 var fbFeedParams = fb.Params{
 	"fields": `
 id,created_time,from,link,message,message_tags,name,object_id,permalink_url,properties,
@@ -25,14 +26,31 @@ comments.summary(true){id,attachment,comment_count,created_time,from,like_count,
 	"limit": "100",
 }
 
+// newGraphBackOff configures the retries of failed Graph API calls
+var newGraphBackOff = func() backoff.BackOff {
+	exponentialBackoff := backoff.NewExponentialBackOff()
+	exponentialBackoff.MaxInterval = 24 * time.Hour
+	return exponentialBackoff
+}
+
+// graphBackOff configures the retries of Graph API calls made with fbSession, which stop once its context is done
+func graphBackOff(fbSession *fb.Session) backoff.BackOff {
+	return backoff.WithContext(newGraphBackOff(), fbSession.Context())
+}
+
 func retryNotifyFunc(err error, duration time.Duration) {
 	log.Println(fmt.Sprintf("Queued for retry after %s, error=%s", duration, err))
 }
 
+// fbAccessTokenMu guards the exchange of the short access token, and the long one it's replaced with
+var fbAccessTokenMu sync.Mutex
+
 func getFbAccessToken(fbApp *fb.App, logger *zap.Logger) string {
-	longAccessToken := GetEnv("FB_LONG_ACCESS_TOKEN", "")
+	fbAccessTokenMu.Lock()
+	defer fbAccessTokenMu.Unlock()
+	longAccessToken := config.FBLongAccessToken
 	if longAccessToken == "" {
-		shortAccessToken := GetEnv("FB_SHORT_ACCESS_TOKEN", "")
+		shortAccessToken := config.FBShortAccessToken
 		if shortAccessToken == "" {
 			return shortAccessToken
 		}
@@ -43,18 +61,20 @@ func getFbAccessToken(fbApp *fb.App, logger *zap.Logger) string {
 			return shortAccessToken
 		}
 
-		// Update env
-		updateEnvFile("FB_LONG_ACCESS_TOKEN", longAccessToken)
-		err = os.Setenv("FB_LONG_ACCESS_TOKEN", longAccessToken)
-		if err != nil {
-			logger.Warn("Unable to write FB long access token to env file", zap.Error(err))
-		}
+		saveFbLongAccessToken(longAccessToken)
 	}
 	return longAccessToken
 }
 
+// saveFbLongAccessToken keeps the long access token for the next sessions, and the next runs. Callers racing with
+// the creation of sessions must hold fbAccessTokenMu.
+func saveFbLongAccessToken(longAccessToken string) {
+	config.FBLongAccessToken = longAccessToken
+	updateEnvFile("FB_LONG_ACCESS_TOKEN", longAccessToken)
+}
+
 func getFacebookSession(logger *zap.Logger) (*fb.Session, error) {
-	var fbApp = fb.New(GetEnv("FB_APP_ID", ""), GetEnv("FB_APP_SECRET", ""))
+	var fbApp = fb.New(config.FBAppID, config.FBAppSecret)
 	fbApp.RedirectUri = "https://beta.lttkgp.com"
 	sessionToken := getFbAccessToken(fbApp, logger)
 	if sessionToken == "" {
@@ -62,39 +82,120 @@ func getFacebookSession(logger *zap.Logger) (*fb.Session, error) {
 	}
 	fbSession := fbApp.Session(sessionToken)
 	fbSession.RFC3339Timestamps = true
+	fbSession.HttpClient = newHTTPClient()
+	fbSession.Version = "v8.0"
+	// Point the session at a stand-in for the Graph API, e.g. pkg/fakegraph
+	if config.FBGraphURL != "" {
+		fbSession.BaseURL = strings.TrimSuffix(config.FBGraphURL, "/") + "/"
+	}
 
 	return fbSession, nil
 }
 
-// FetchLatestPosts bootstraps the DB with Facebook posts
-func FetchLatestPosts(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) error {
+// decodeFeedPost extracts the stored fields of a post from the group feed
+func decodeFeedPost(post fb.Result, logger *zap.Logger) (PostData, error) {
+	// Read keys
+	var keyMetadata KeyMetadata
+	err := post.Decode(&keyMetadata)
+	if err != nil {
+		logger.Error("Failed to decode key metadata from Facebook post", zap.Error(err))
+		return PostData{}, err
+	}
+	logger.Debug("Extracted key metadata from Facebook post", zap.Object("keyMetadata", keyMetadata))
+
+	return PostData{
+		CreatedTime:  keyMetadata.CreatedTime,
+		FacebookID:   keyMetadata.FacebookID,
+		FacebookPost: post,
+		UpdatedTime:  keyMetadata.UpdatedTime,
+	}, nil
+}
+
+// storeFeedPost stores a post from the feed if it's new or was updated, along with its comment thread and the
+// music links shared in both, and queues the matching event for webhook subscribers. New comments bump the
+// updated_time of a post.
+func storeFeedPost(ctx context.Context, fbSession *fb.Session, store PostStore, postData PostData, logger *zap.Logger) error {
+	ctx, span := startSpan(ctx, "storeFeedPost", trace.WithAttributes(label.String("facebook_id", postData.FacebookID)))
+	defer span.End()
+
+	stored, err := store.GetPost(ctx, postData.FacebookID)
+	if err != nil && !errors.Is(err, ErrPostNotFound) {
+		return err
+	}
+	event := webhookEventUpdated
+	if err != nil {
+		event = webhookEventNew
+	}
+	if err == nil && !isPostChanged(stored, postData) {
+		postUpserts.WithLabelValues("unchanged").Inc()
+		return nil
+	}
+
+	// Comments are stored first, so that the links shared in them are stored with the post. The post isn't stored
+	// without its comments, so that the next fetch sees it changed and tries again.
+	if err := ingestComments(ctx, fbSession, store, postData.FacebookID, logger); err != nil {
+		logger.Warn("Failed to store comments of Facebook post", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+		postUpserts.WithLabelValues("error").Inc()
+		return err
+	}
+	comments, err := store.ListComments(ctx, postData.FacebookID)
+	if err != nil {
+		logger.Warn("Failed to read comments of Facebook post", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+	}
+	postData.Links = extractMusicLinks(postData, comments)
+
+	_, upsertSpan := startSpan(ctx, "UpdateOrInsertPost")
+	err = store.UpdateOrInsertPost(ctx, postData)
+	endSpan(upsertSpan, err)
+	if err != nil {
+		postUpserts.WithLabelValues("error").Inc()
+		return err
+	}
+	postUpserts.WithLabelValues("stored").Inc()
+	queueWebhookEvent(ctx, store, postData.FacebookID, event, logger)
+	return nil
+}
+
+// FetchLatestPosts bootstraps the DB with Facebook posts. Graph API calls are cancelled along with ctx.
+func FetchLatestPosts(ctx context.Context, store PostStore, logger *zap.Logger) error {
 	// Initialize Facebook session
 	fbSession, err := getFacebookSession(logger)
 	if err != nil {
-		logger.Fatal("Unable to create Facebook session", zap.Error(err))
+		logger.Error("Unable to create Facebook session", zap.Error(err))
+		return err
 	}
-	fbSession.Version = "v8.0"
 	logger.Debug("Created Facebook session", zap.Any("fbSession", fbSession))
 
+	ctx, span := startSpan(ctx, "FetchLatestPosts")
+	err = fetchFeed(ctx, fbSession.WithContext(ctx), store, logger)
+	endSpan(span, err)
+	return err
+}
+
+// fetchFeed pages through the group feed and stores up to LATEST_CHECK_THRESHOLD posts
+func fetchFeed(ctx context.Context, fbSession *fb.Session, store PostStore, logger *zap.Logger) error {
 	// Keep count of parsed posts
 	parsedCount := 0
-	latestCheckThreshold := GetEnv("LATEST_CHECK_THRESHOLD", "300")
-	maxParsedCount, err := strconv.Atoi(latestCheckThreshold)
-	if err != nil {
-		maxParsedCount = 300
-	}
+	maxParsedCount := config.LatestCheckThreshold
+
+	// Engagement of young posts is sampled on every fetch
+	sampledAt := time.Now()
+	snapshotMaxAge := engagementSnapshotMaxAge()
 
 	// Configure exponential backoff for retries
-	exponentialBackoff := backoff.NewExponentialBackOff()
-	exponentialBackoff.MaxInterval = 24 * time.Hour
+	exponentialBackoff := graphBackOff(fbSession)
 
 	// Fetch the first page of response
 	var feedResp fb.Result
-	err = backoff.RetryNotify(func() error {
+	page := 0
+	_, pageSpan := startSpan(ctx, "feed page", trace.WithAttributes(label.Int("page", page)))
+	err := backoff.RetryNotify(func() error {
 		var fbError error
 		feedResp, fbError = fbSession.Get(fmt.Sprintf("%s/feed", fbGroupID), fbFeedParams)
+		countGraphError(fbError)
 		return fbError
 	}, exponentialBackoff, retryNotifyFunc)
+	endSpan(pageSpan, err)
 	if err != nil {
 		logger.Warn("Unable to schedule retry for feed fetch", zap.Error(err))
 		return err
@@ -107,26 +208,23 @@ func FetchLatestPosts(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) erro
 
 	// Iterate through page results
 	for {
-		// Iterate through posts in page
+		// Iterate through posts in page, until the run is cancelled
 		for _, post := range paging.Data() {
-			// Read keys
-			var keyMetadata KeyMetadata
-			err := post.Decode(&keyMetadata)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			postsFetched.WithLabelValues("feed").Inc()
+			postData, err := decodeFeedPost(post, logger)
 			if err != nil {
-				logger.Error("Failed to decode key metadata from Facebook post", zap.Error(err))
 				continue
 			}
-			logger.Debug("Extracted key metadata from Facebook post", zap.Object("keyMetadata", keyMetadata))
-
-			postData := PostData{
-				CreatedTime:  keyMetadata.CreatedTime,
-				FacebookID:   keyMetadata.FacebookID,
-				FacebookPost: post,
-				IsParsed:     "false",
-			}
 
 			// Insert post to DB
-			UpdateOrInsertPost(dynamoSession, postData, logger)
+			err = storeFeedPost(ctx, fbSession, store, postData, logger)
+			if err != nil {
+				logger.Warn("Failed to store Facebook post", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+			}
+			recordEngagementSnapshot(ctx, store, postData, sampledAt, snapshotMaxAge, logger)
 			parsedCount++
 		}
 
@@ -135,12 +233,19 @@ func FetchLatestPosts(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) erro
 		}
 
 		// Break on last page
+		if !paging.HasNext() {
+			break
+		}
 		var noMore bool
+		page++
+		_, pageSpan := startSpan(ctx, "feed page", trace.WithAttributes(label.Int("page", page)))
 		err := backoff.RetryNotify(func() error {
 			var pagingError error
 			noMore, pagingError = paging.Next()
+			countGraphError(pagingError)
 			return pagingError
 		}, exponentialBackoff, retryNotifyFunc)
+		endSpan(pageSpan, err)
 		if err != nil {
 			logger.Error("Failed paging through Facebook response", zap.Error(err))
 			return err
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/fakegraph"
+	"go.uber.org/zap"
+)
+
+// retryGraphWithoutWaiting retries failed Graph API calls up to maxRetries times, back to back
+func retryGraphWithoutWaiting(t *testing.T, maxRetries uint64) {
+	defaultGraphBackOff := newGraphBackOff
+	newGraphBackOff = func() backoff.BackOff {
+		return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, maxRetries)
+	}
+	t.Cleanup(func() { newGraphBackOff = defaultGraphBackOff })
+}
+
+// addTestFeedPages adds pages of posts, numbered from 1 across pages
+func addTestFeedPages(graph *fakegraph.Server, pageSizes ...int) {
+	postNumber := 0
+	for _, pageSize := range pageSizes {
+		var posts []fb.Result
+		for i := 0; i < pageSize; i++ {
+			postNumber++
+			posts = append(posts, fb.Result{
+				"id":           fmt.Sprintf("%s_%d", fbGroupID, postNumber),
+				"created_time": fmt.Sprintf("2020-10-%02dT00:00:00+00:00", postNumber),
+			})
+		}
+		graph.AddFeedPage(posts...)
+	}
+}
+
+func TestFetchFeed(t *testing.T) {
+	graph := fakegraph.New()
+	defer graph.Close()
+	if err := graph.LoadFeedPages("testdata/feed-page-1.json", "testdata/feed-page-2.json"); err != nil {
+		t.Fatal(err)
+	}
+	graph.AddComments(fbGroupID+"_3000000000000003",
+		fb.Result{"id": "c1", "created_time": "2020-10-03T19:00:00+00:00", "message": "Also https://artist.bandcamp.com/album/record"},
+		fb.Result{"id": "c2", "created_time": "2020-10-03T19:05:00+00:00", "message": "thanks", "parent": fb.Result{"id": "c1"}},
+	)
+	store := NewMemoryPostStore()
+
+	if err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if cursors := graph.FeedRequests(); strings.Join(cursors, ",") != ",1" {
+		t.Errorf("Expected both feed pages to be fetched, requested %v", cursors)
+	}
+	page, err := store.ListPosts(context.Background(), PostQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 3 {
+		t.Fatalf("Expected every post of the feed to be stored, got %d", len(page.Posts))
+	}
+	for _, postData := range page.Posts {
+		delivery := c3poDeliveryOf(t, store, postData.FacebookID)
+		if delivery.Status != webhookDeliveryPending || delivery.Event != webhookEventNew || len(postData.Links) == 0 {
+			t.Errorf("Expected %s to be queued with its music links, got %+v and %+v", postData.FacebookID, postData, delivery)
+		}
+	}
+	comments, err := store.ListComments(context.Background(), fbGroupID+"_3000000000000003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[1].ParentID != "c1" {
+		t.Errorf("Expected the comment thread to be stored, got %+v", comments)
+	}
+	if postData, _ := store.GetPost(context.Background(), fbGroupID+"_3000000000000003"); len(postData.Links) != 2 {
+		t.Errorf("Expected the links of the post and its comments, got %+v", postData.Links)
+	}
+}
+
+func TestFetchFeedThreshold(t *testing.T) {
+	setTestConfig(t, func(c *Config) { c.LatestCheckThreshold = 3 })
+	graph := fakegraph.New()
+	defer graph.Close()
+	addTestFeedPages(graph, 2, 2, 2)
+	store := NewMemoryPostStore()
+
+	if err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	// The threshold is checked after every page, so the page crossing it is fetched whole
+	if cursors := graph.FeedRequests(); strings.Join(cursors, ",") != ",1" {
+		t.Errorf("Expected the fetch to stop on the page crossing the threshold, requested %v", cursors)
+	}
+	if page, _ := store.ListPosts(context.Background(), PostQuery{Limit: 10}); len(page.Posts) != 4 {
+		t.Errorf("Expected the posts of the first two pages, got %d", len(page.Posts))
+	}
+}
+
+func TestFetchFeedRetries(t *testing.T) {
+	retryGraphWithoutWaiting(t, 3)
+	graph := fakegraph.New()
+	defer graph.Close()
+	addTestFeedPages(graph, 1, 1)
+	graph.FailNext("/feed", 2, fakegraph.RateLimitError())
+	store := NewMemoryPostStore()
+
+	if err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop()); err != nil {
+		t.Fatalf("Expected rate limited feed requests to be retried, got %v", err)
+	}
+	if cursors := graph.FeedRequests(); strings.Join(cursors, ",") != ",,,1" {
+		t.Errorf("Expected the first page to be requested until it succeeds, requested %v", cursors)
+	}
+	if page, _ := store.ListPosts(context.Background(), PostQuery{Limit: 10}); len(page.Posts) != 2 {
+		t.Errorf("Expected both posts to be stored, got %d", len(page.Posts))
+	}
+
+	// A feed failing past the retries fails the fetch
+	graph = fakegraph.New()
+	defer graph.Close()
+	addTestFeedPages(graph, 1, 1)
+	graph.FailNext("/feed", 5, fakegraph.RateLimitError())
+	store = NewMemoryPostStore()
+	err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop())
+	var fbError *fb.Error
+	if !errors.As(err, &fbError) || fbError.Code != 4 {
+		t.Errorf("Expected the rate limit error once retries are exhausted, got %v", err)
+	}
+	if cursors := graph.FeedRequests(); len(cursors) != 4 {
+		t.Errorf("Expected the first try and 3 retries, requested %v", cursors)
+	}
+}
+
+func TestFetchFeedCommentsFailure(t *testing.T) {
+	retryGraphWithoutWaiting(t, 1)
+	graph := fakegraph.New()
+	defer graph.Close()
+	addTestFeedPages(graph, 1)
+	postID := fbGroupID + "_1"
+	graph.AddComments(postID, fb.Result{"id": "c1", "created_time": "2020-10-01T10:00:00+00:00", "message": "first"})
+	graph.FailNext("/comments", 2, fakegraph.RateLimitError())
+	store := NewMemoryPostStore()
+
+	if err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPost(context.Background(), postID); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("Expected the post to be left for the next fetch without its comments, got %v", err)
+	}
+
+	// The next fetch stores the post with its comments
+	if err := fetchFeed(context.Background(), graph.Session(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPost(context.Background(), postID); err != nil {
+		t.Errorf("Expected the post to be stored, got %v", err)
+	}
+	if comments, _ := store.ListComments(context.Background(), postID); len(comments) != 1 {
+		t.Errorf("Expected the comments to be stored, got %+v", comments)
+	}
+}
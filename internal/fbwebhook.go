@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// verifyHubSignature checks the X-Hub-Signature-256 header of a Facebook Webhooks notification against its body
+func verifyHubSignature(appSecret string, body []byte, signatureHeader string) bool {
+	if appSecret == "" || !strings.HasPrefix(signatureHeader, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	_, _ = mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signatureHeader))
+}
+
+// isPostRemoval reports whether a feed change removes the post itself, rather than one of its comments or reactions
+func isPostRemoval(change FacebookWebhookChange) bool {
+	return change.Value.Verb == "remove" && change.Value.Item != "comment" && change.Value.Item != "reaction"
+}
+
+// refreshPost fetches a post from the Graph API and stores it, or tombstones it if it was deleted in the meantime
+func refreshPost(ctx context.Context, fbSession *fb.Session, store PostStore, facebookID string, logger *zap.Logger) error {
+	post, err := fbSession.Get(facebookID, fb.Params{"fields": fbFeedParams["fields"]})
+	countGraphError(err)
+	if isDeletedPostError(err) {
+		return removePost(ctx, store, facebookID, logger)
+	}
+	if err != nil {
+		return err
+	}
+	postsFetched.WithLabelValues("webhook").Inc()
+	postData, err := decodeFeedPost(post, logger)
+	if err != nil {
+		return err
+	}
+	return storeFeedPost(ctx, fbSession, store, postData, logger)
+}
+
+// removePost tombstones a stored post that was removed from the group, which queues the deletion for C-3PO and the
+// webhook subscribers
+func removePost(ctx context.Context, store PostStore, facebookID string, logger *zap.Logger) error {
+	postData, err := store.GetPost(ctx, facebookID)
+	if errors.Is(err, ErrPostNotFound) {
+		logger.Debug("Removed post was never stored", zap.String("FacebookID", facebookID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if postData.DeletedTime != nil {
+		return nil
+	}
+
+	return tombstonePost(ctx, store, postData, logger)
+}
+
+// processFacebookWebhook stores or tombstones every post of the group mentioned by a notification. Posts that
+// fail are left to the scheduled fetch and reconciliation.
+func processFacebookWebhook(ctx context.Context, fbSession *fb.Session, store PostStore, notification FacebookWebhookNotification, logger *zap.Logger) {
+	ctx, span := startSpan(ctx, "processFacebookWebhook")
+	defer span.End()
+
+	// A notification can batch several changes of the same post, handle each post once
+	removed := map[string]bool{}
+	var postIDs []string
+	for _, entry := range notification.Entry {
+		if entry.ID != fbGroupID {
+			logger.Warn("Ignoring Facebook webhook entry of another object", zap.String("id", entry.ID))
+			continue
+		}
+		for _, change := range entry.Changes {
+			if change.Field != "feed" || change.Value.PostID == "" {
+				continue
+			}
+			if _, seen := removed[change.Value.PostID]; !seen {
+				postIDs = append(postIDs, change.Value.PostID)
+			}
+			removed[change.Value.PostID] = isPostRemoval(change)
+		}
+	}
+
+	for _, facebookID := range postIDs {
+		var err error
+		if removed[facebookID] {
+			err = removePost(ctx, store, facebookID, logger)
+		} else {
+			err = refreshPost(ctx, fbSession, store, facebookID, logger)
+		}
+		if err != nil {
+			logger.Warn("Failed to handle Facebook webhook change", zap.String("FacebookID", facebookID), zap.Error(err))
+		}
+	}
+	logger.Info("Processed Facebook webhook notification", zap.Int("posts", len(postIDs)))
+}
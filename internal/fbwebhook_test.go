@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// newTestWebhookServer serves the API with Facebook webhook notifications handled against a fake Graph API
+// where every post other than the deleted ones exists. Notifications are processed with the returned runner.
+func newTestWebhookServer(t *testing.T, store PostStore, deletedIDs map[string]bool) (*httptest.Server, *backfillRunner) {
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/comments") {
+			_, _ = w.Write([]byte(`{"data": []}`))
+			return
+		}
+		facebookID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if deletedIDs[facebookID] {
+			_, _ = w.Write([]byte(`{"error": {"message": "Unsupported get request.", "type": "GraphMethodException", "code": 100, "error_subcode": 33}}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id": "%s", "created_time": "2020-10-01T00:00:00+00:00", "updated_time": "2020-10-01T01:00:00+00:00", "message": "song"}`, facebookID)
+	}))
+	t.Cleanup(graph.Close)
+	getSession := func(*zap.Logger) (*fb.Session, error) {
+		fbSession := fb.New("app", "secret").Session("token")
+		fbSession.BaseURL = graph.URL + "/"
+		fbSession.Version = "v8.0"
+		return fbSession, nil
+	}
+
+	api, err := newAPI(context.Background(), store, NewDispatcher(store, zap.NewNop()), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	background := &backfillRunner{ctx: context.Background(), backfill: Backfill}
+	api.ReceiveFacebookWebhookHandler = ReceiveFacebookWebhookHandler(store, getSession, background, zap.NewNop())
+	server := httptest.NewServer(api.Serve(nil))
+	t.Cleanup(server.Close)
+	return server, background
+}
+
+func postWebhook(t *testing.T, url string, body string, signature string) int {
+	req, err := http.NewRequest("POST", url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestVerifyFacebookWebhook(t *testing.T) {
+	setTestConfig(t, func(c *Config) { c.FBWebhookVerifyToken = "token" })
+	server := newTestAPIServer(t, NewMemoryPostStore())
+
+	resp, err := http.Get(server.URL + "/webhooks/facebook?hub.mode=subscribe&hub.verify_token=token&hub.challenge=1158201444")
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(challenge) != "1158201444" {
+		t.Errorf("Expected the challenge to be echoed, got %d %q", resp.StatusCode, challenge)
+	}
+
+	resp, err = http.Get(server.URL + "/webhooks/facebook?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1158201444")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a wrong verify token to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestReceiveFacebookWebhook(t *testing.T) {
+	c3po := newTestC3po(t, true)
+	setTestConfig(t, func(c *Config) {
+		c.FBAppSecret = "secret"
+		c.C3POURI = c3po.URL
+	})
+
+	store := NewMemoryPostStore()
+	_ = store.UpdateOrInsertPost(context.Background(), PostData{CreatedTime: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC), FacebookID: fbGroupID + "_2"})
+	_ = store.UpdateOrInsertPost(context.Background(), PostData{CreatedTime: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC), FacebookID: fbGroupID + "_3"})
+	server, background := newTestWebhookServer(t, store, map[string]bool{fbGroupID + "_3": true})
+	url := server.URL + "/webhooks/facebook"
+
+	body := fmt.Sprintf(`{"object": "group", "entry": [{"id": "%[1]s", "time": 1601514000, "changes": [
+		{"field": "feed", "value": {"item": "status", "post_id": "%[1]s_1", "verb": "add"}},
+		{"field": "feed", "value": {"item": "comment", "post_id": "%[1]s_1", "verb": "add"}},
+		{"field": "feed", "value": {"item": "status", "post_id": "%[1]s_2", "verb": "remove"}},
+		{"field": "feed", "value": {"item": "comment", "post_id": "%[1]s_3", "verb": "edited"}}
+	]}]}`, fbGroupID)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if status := postWebhook(t, url, body, ""); status != http.StatusForbidden {
+		t.Errorf("Expected an unsigned notification to be rejected, got %d", status)
+	}
+	if status := postWebhook(t, url, body, "sha256=00"); status != http.StatusForbidden {
+		t.Errorf("Expected a wrongly signed notification to be rejected, got %d", status)
+	}
+	if _, err := store.GetPost(context.Background(), fbGroupID+"_1"); err != ErrPostNotFound {
+		t.Fatalf("Expected rejected notifications not to store anything, got %v", err)
+	}
+
+	if status := postWebhook(t, url, body, signature); status != http.StatusOK {
+		t.Fatalf("Expected the notification to be accepted, got %d", status)
+	}
+	if !background.Wait(5 * time.Second) {
+		t.Fatal("Expected the notification to be processed in the background")
+	}
+	added, err := store.GetPost(context.Background(), fbGroupID+"_1")
+	if err != nil || added.FacebookPost["message"] != "song" || c3poDeliveryOf(t, store, fbGroupID+"_1").Status != webhookDeliveryPending {
+		t.Errorf("Expected the added post to be stored and queued, got %+v, %v", added, err)
+	}
+	for _, facebookID := range []string{fbGroupID + "_2", fbGroupID + "_3"} {
+		removed, _ := store.GetPost(context.Background(), facebookID)
+		delivery := c3poDeliveryOf(t, store, facebookID)
+		if removed.DeletedTime == nil || delivery.Event != webhookEventDeleted || delivery.Status != webhookDeliveryPending {
+			t.Errorf("Expected %s to be tombstoned and its deletion queued for C-3PO, got %+v and %+v", facebookID, removed, delivery)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// Music providers recognized in links
+const (
+	providerYouTube    = "youtube"
+	providerSpotify    = "spotify"
+	providerSoundCloud = "soundcloud"
+	providerAppleMusic = "apple_music"
+	providerBandcamp   = "bandcamp"
+)
+
+// Where a music link was found
+const (
+	linkSourceLink    = "link"
+	linkSourceMessage = "message"
+	linkSourceComment = "comment"
+)
+
+// urlPattern finds URLs in free text, with or without a scheme
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.|youtu\.be/|open\.spotify\.com/|soundcloud\.com/|music\.apple\.com/)[^\s<>"']+`)
+
+// spotifyURIPattern finds Spotify URIs, e.g. spotify:track:4uLU6hMCjMI75M1A2tKUQC
+var spotifyURIPattern = regexp.MustCompile(`\bspotify:(track|album|playlist|artist|episode|show):([A-Za-z0-9]{22})\b`)
+
+var youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+var spotifyIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{22}$`)
+
+// spotifyKinds are the Spotify objects worth linking to
+var spotifyKinds = map[string]bool{"track": true, "album": true, "playlist": true, "artist": true, "episode": true, "show": true}
+
+// appleMusicKinds are the Apple Music objects worth linking to
+var appleMusicKinds = map[string]bool{"album": true, "playlist": true, "artist": true, "song": true, "music-video": true}
+
+// findMusicLinks extracts the normalized music links of a text, in order of appearance
+func findMusicLinks(text string, source string) []MusicLink {
+	type match struct {
+		start int
+		link  MusicLink
+	}
+	var matches []match
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		rawURL := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?)]}*")
+		if link, ok := normalizeMusicLink(rawURL); ok {
+			link.Source = source
+			matches = append(matches, match{loc[0], link})
+		}
+	}
+	for _, loc := range spotifyURIPattern.FindAllStringSubmatchIndex(text, -1) {
+		kind, id := text[loc[2]:loc[3]], text[loc[4]:loc[5]]
+		matches = append(matches, match{loc[0], MusicLink{
+			Provider: providerSpotify,
+			URL:      "https://open.spotify.com/" + kind + "/" + id,
+			ID:       id,
+			Source:   source,
+		}})
+	}
+
+	// Both patterns are scanned separately, so restore the order of the text
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+	links := make([]MusicLink, 0, len(matches))
+	for _, match := range matches {
+		links = append(links, match.link)
+	}
+	return links
+}
+
+// normalizeMusicLink recognizes a link to a music provider and returns its canonical form, without tracking
+// parameters. Facebook's outbound link redirects are unwrapped first.
+func normalizeMusicLink(rawURL string) (MusicLink, bool) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	linkURL, err := url.Parse(rawURL)
+	if err != nil {
+		return MusicLink{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(linkURL.Hostname()), "www.")
+	if (host == "l.facebook.com" || host == "lm.facebook.com") && linkURL.Query().Get("u") != "" {
+		target := linkURL.Query().Get("u")
+		if strings.Contains(target, "facebook.com/l.php") {
+			return MusicLink{}, false
+		}
+		return normalizeMusicLink(target)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(linkURL.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	switch {
+	case host == "youtu.be":
+		if len(segments) > 0 {
+			return youTubeVideoLink(segments[0])
+		}
+	case host == "youtube.com" || host == "m.youtube.com" || host == "music.youtube.com":
+		switch {
+		case len(segments) == 1 && segments[0] == "watch":
+			return youTubeVideoLink(linkURL.Query().Get("v"))
+		case len(segments) == 1 && segments[0] == "playlist" && linkURL.Query().Get("list") != "":
+			playlistID := linkURL.Query().Get("list")
+			return MusicLink{
+				Provider: providerYouTube,
+				URL:      "https://www.youtube.com/playlist?list=" + url.QueryEscape(playlistID),
+				ID:       playlistID,
+			}, true
+		case len(segments) == 2 && (segments[0] == "shorts" || segments[0] == "embed" || segments[0] == "v"):
+			return youTubeVideoLink(segments[1])
+		}
+	case host == "open.spotify.com" || host == "play.spotify.com":
+		// Skip locale and embed prefixes, e.g. /intl-fr/track/<id> or /embed/track/<id>
+		for len(segments) > 0 && (strings.HasPrefix(segments[0], "intl-") || segments[0] == "embed") {
+			segments = segments[1:]
+		}
+		if len(segments) == 2 && spotifyKinds[segments[0]] && spotifyIDPattern.MatchString(segments[1]) {
+			return MusicLink{
+				Provider: providerSpotify,
+				URL:      "https://open.spotify.com/" + segments[0] + "/" + segments[1],
+				ID:       segments[1],
+			}, true
+		}
+	case host == "soundcloud.com" || host == "m.soundcloud.com" || host == "on.soundcloud.com":
+		if len(segments) > 0 {
+			canonicalHost := "soundcloud.com"
+			if host == "on.soundcloud.com" {
+				canonicalHost = host
+			}
+			return MusicLink{
+				Provider: providerSoundCloud,
+				URL:      "https://" + canonicalHost + "/" + strings.Join(segments, "/"),
+			}, true
+		}
+	case host == "music.apple.com" || host == "itunes.apple.com":
+		// /<country>/<kind>/<slug>/<id>, with ?i=<track id> for a track of an album
+		if len(segments) >= 3 && appleMusicKinds[segments[1]] {
+			link := MusicLink{
+				Provider: providerAppleMusic,
+				URL:      "https://music.apple.com/" + strings.Join(segments, "/"),
+				ID:       strings.TrimPrefix(segments[len(segments)-1], "id"),
+			}
+			if trackID := linkURL.Query().Get("i"); trackID != "" {
+				link.URL += "?i=" + url.QueryEscape(trackID)
+				link.ID = trackID
+			}
+			return link, true
+		}
+	case strings.HasSuffix(host, ".bandcamp.com"):
+		if len(segments) == 2 && (segments[0] == "track" || segments[0] == "album") {
+			return MusicLink{
+				Provider: providerBandcamp,
+				URL:      "https://" + host + "/" + segments[0] + "/" + segments[1],
+			}, true
+		}
+	}
+	return MusicLink{}, false
+}
+
+// youTubeVideoLink builds the canonical link of a YouTube video
+func youTubeVideoLink(videoID string) (MusicLink, bool) {
+	if !youTubeIDPattern.MatchString(videoID) {
+		return MusicLink{}, false
+	}
+	return MusicLink{
+		Provider: providerYouTube,
+		URL:      "https://www.youtube.com/watch?v=" + videoID,
+		ID:       videoID,
+	}, true
+}
+
+// extractMusicLinks collects the music links of a post from its `link`, its `message` and its comments, keeping
+// the first occurrence of every link
+func extractMusicLinks(postData PostData, comments []CommentData) []MusicLink {
+	var links []MusicLink
+	if link, ok := postData.FacebookPost["link"].(string); ok {
+		if musicLink, ok := normalizeMusicLink(link); ok {
+			musicLink.Source = linkSourceLink
+			links = append(links, musicLink)
+		}
+	}
+	if message, ok := postData.FacebookPost["message"].(string); ok {
+		links = append(links, findMusicLinks(message, linkSourceMessage)...)
+	}
+	for _, comment := range comments {
+		links = append(links, commentMusicLinks(comment.FacebookComment)...)
+	}
+
+	seen := map[string]bool{}
+	uniqueLinks := make([]MusicLink, 0, len(links))
+	for _, link := range links {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			uniqueLinks = append(uniqueLinks, link)
+		}
+	}
+	return uniqueLinks
+}
+
+// commentMusicLinks extracts the music links of a comment from its message and its link attachment
+func commentMusicLinks(comment fb.Result) []MusicLink {
+	var links []MusicLink
+	if message, ok := comment["message"].(string); ok {
+		links = append(links, findMusicLinks(message, linkSourceComment)...)
+	}
+	for _, field := range []string{"attachment.target.url", "attachment.url"} {
+		if attachmentURL, ok := comment.Get(field).(string); ok {
+			if link, ok := normalizeMusicLink(attachmentURL); ok {
+				link.Source = linkSourceComment
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+func TestNormalizeMusicLink(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rawURL   string
+		expected MusicLink
+	}{
+		{"youtu.be short link", "https://youtu.be/dQw4w9WgXcQ?si=abcdef", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ"}},
+		{"youtube watch link", "https://m.youtube.com/watch?v=dQw4w9WgXcQ&utm_source=fb&t=42", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ"}},
+		{"youtube music link", "https://music.youtube.com/watch?v=dQw4w9WgXcQ&feature=share", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ"}},
+		{"youtube shorts link", "youtube.com/shorts/dQw4w9WgXcQ", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ"}},
+		{"youtube playlist", "https://www.youtube.com/playlist?list=PL123&si=xyz", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/playlist?list=PL123", ID: "PL123"}},
+		{"spotify localized link", "https://open.spotify.com/intl-fr/track/4uLU6hMCjMI75M1A2tKUQC?si=1234", MusicLink{Provider: providerSpotify, URL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", ID: "4uLU6hMCjMI75M1A2tKUQC"}},
+		{"soundcloud mobile link", "https://m.soundcloud.com/artist/song?utm_source=clipboard&in=artist/sets/x", MusicLink{Provider: providerSoundCloud, URL: "https://soundcloud.com/artist/song"}},
+		{"apple music track of album", "https://music.apple.com/in/album/never-gonna-give-you-up/1558533900?i=1558534271&ls", MusicLink{Provider: providerAppleMusic, URL: "https://music.apple.com/in/album/never-gonna-give-you-up/1558533900?i=1558534271", ID: "1558534271"}},
+		{"bandcamp track", "https://artist.bandcamp.com/track/song?from=embed", MusicLink{Provider: providerBandcamp, URL: "https://artist.bandcamp.com/track/song"}},
+		{"facebook redirect", "https://l.facebook.com/l.php?u=https%3A%2F%2Fyoutu.be%2FdQw4w9WgXcQ%3Ffbclid%3Dabc&h=AT0", MusicLink{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ"}},
+		{"youtube channel", "https://www.youtube.com/channel/UC38IQsAvIsxxjztdMZQtwHA", MusicLink{}},
+		{"spotify user", "https://open.spotify.com/user/spotify", MusicLink{}},
+		{"other link", "https://example.com/watch?v=dQw4w9WgXcQ", MusicLink{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			link, ok := normalizeMusicLink(testCase.rawURL)
+			if ok != (testCase.expected != MusicLink{}) || link != testCase.expected {
+				t.Errorf("Expected %+v, got %+v (ok=%v)", testCase.expected, link, ok)
+			}
+		})
+	}
+}
+
+func TestExtractMusicLinks(t *testing.T) {
+	postData := PostData{
+		FacebookID: "1_1",
+		FacebookPost: fb.Result{
+			"link":    "https://youtu.be/dQw4w9WgXcQ",
+			"message": "Same song (https://www.youtube.com/watch?v=dQw4w9WgXcQ&feature=share), also on spotify:track:4uLU6hMCjMI75M1A2tKUQC.",
+		},
+	}
+	comments := []CommentData{
+		{FacebookComment: fb.Result{"message": "Live version: soundcloud.com/artist/live-song"}},
+		{FacebookComment: fb.Result{
+			"message":    "nice",
+			"attachment": map[string]interface{}{"url": "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"},
+		}},
+		{FacebookComment: fb.Result{
+			"attachment": map[string]interface{}{"target": map[string]interface{}{"url": "https://artist.bandcamp.com/album/record"}},
+		}},
+	}
+
+	links := extractMusicLinks(postData, comments)
+	expected := []MusicLink{
+		{Provider: providerYouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ID: "dQw4w9WgXcQ", Source: linkSourceLink},
+		{Provider: providerSpotify, URL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", ID: "4uLU6hMCjMI75M1A2tKUQC", Source: linkSourceMessage},
+		{Provider: providerSoundCloud, URL: "https://soundcloud.com/artist/live-song", Source: linkSourceComment},
+		{Provider: providerBandcamp, URL: "https://artist.bandcamp.com/album/record", Source: linkSourceComment},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Errorf("Expected link %d to be %+v, got %+v", i, expected[i], links[i])
+		}
+	}
+}
+
+func TestStoreFeedPostExtractsMusicLinks(t *testing.T) {
+	store := NewMemoryPostStore()
+	postData := PostData{
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"id": "1_1", "message": "https://open.spotify.com/album/1DFixLWuPkv3KT3TnV35m3?si=x"},
+	}
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": [{"id": "1_1_c1", "created_time": "2020-10-01T00:00:00Z", "message": "https://youtu.be/dQw4w9WgXcQ"}]}`))
+	}))
+	t.Cleanup(graph.Close)
+	fbSession := fb.New("app", "secret").Session("token")
+	fbSession.BaseURL = graph.URL + "/"
+
+	if err := storeFeedPost(context.Background(), fbSession, store, postData, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := store.GetPost(context.Background(), "1_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Links) != 2 || stored.Links[0].URL != "https://open.spotify.com/album/1DFixLWuPkv3KT3TnV35m3" ||
+		stored.Links[1].URL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("Expected the links of the post and its comment to be stored with the post, got %+v", stored.Links)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryPostStore is a PostStore that keeps posts in process memory. Useful for tests and local runs.
+type MemoryPostStore struct {
+	mu    sync.RWMutex
+	posts map[string]PostData
+
+	comments            map[string]map[string]CommentData
+	engagementSnapshots map[string][]EngagementSnapshot
+	backfillState       *BackfillState
+	webhookDeliveries   map[string]map[string]WebhookDelivery
+}
+
+// NewMemoryPostStore creates an empty in-memory PostStore
+func NewMemoryPostStore() *MemoryPostStore {
+	return &MemoryPostStore{
+		posts:               map[string]PostData{},
+		comments:            map[string]map[string]CommentData{},
+		engagementSnapshots: map[string][]EngagementSnapshot{},
+		webhookDeliveries:   map[string]map[string]WebhookDelivery{},
+	}
+}
+
+// UpdateOrInsertPost stores the post if it's new or was edited
+func (s *MemoryPostStore) UpdateOrInsertPost(_ context.Context, postData PostData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.posts[postData.FacebookID]; ok {
+		if !isPostChanged(existing, postData) {
+			return nil
+		}
+		postData = withStoredFields(postData, existing)
+	}
+	s.posts[postData.FacebookID] = postData
+	return nil
+}
+
+// MarkPostAsDeleted tombstones a stored post
+func (s *MemoryPostStore) MarkPostAsDeleted(_ context.Context, postData PostData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.posts[postData.FacebookID]
+	if !ok {
+		return ErrPostNotFound
+	}
+	existing.DeletedTime = postData.DeletedTime
+	s.posts[postData.FacebookID] = existing
+	return nil
+}
+
+// RecordDispatch appends a dispatch attempt to the post's history, dropping the oldest beyond maxDispatchHistory
+func (s *MemoryPostStore) RecordDispatch(_ context.Context, postData PostData, record DispatchRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.posts[postData.FacebookID]
+	if !ok {
+		return ErrPostNotFound
+	}
+	// The history is copied, so that posts handed out earlier don't change under the caller
+	existing.DispatchHistory = appendDispatchRecord(existing.DispatchHistory, record)
+	s.posts[postData.FacebookID] = existing
+	return nil
+}
+
+// GetPost fetches a post by its Facebook ID
+func (s *MemoryPostStore) GetPost(_ context.Context, facebookID string) (PostData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	postData, ok := s.posts[facebookID]
+	if !ok {
+		return PostData{}, ErrPostNotFound
+	}
+	return postData, nil
+}
+
+// ListPosts returns a page of posts matching the query, newest first
+func (s *MemoryPostStore) ListPosts(_ context.Context, query PostQuery) (PostPage, error) {
+	s.mu.RLock()
+	posts := make([]PostData, 0, len(s.posts))
+	c3poStatuses := map[string]string{}
+	for _, postData := range s.posts {
+		posts = append(posts, postData)
+		if delivery, ok := s.webhookDeliveries[postData.FacebookID][c3poSubscriberName]; ok {
+			c3poStatuses[postData.FacebookID] = delivery.Status
+		}
+	}
+	s.mu.RUnlock()
+
+	return paginatePosts(posts, c3poStatuses, query)
+}
+
+// UpdateOrInsertComment stores a comment of a post, overwriting any previous version
+func (s *MemoryPostStore) UpdateOrInsertComment(_ context.Context, comment CommentData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.comments[comment.PostID] == nil {
+		s.comments[comment.PostID] = map[string]CommentData{}
+	}
+	s.comments[comment.PostID][comment.FacebookID] = comment
+	return nil
+}
+
+// DeleteComment removes a stored comment of a post, if any
+func (s *MemoryPostStore) DeleteComment(_ context.Context, postID string, commentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.comments[postID], commentID)
+	return nil
+}
+
+// ListComments returns the comments of a post, replies included, oldest first
+func (s *MemoryPostStore) ListComments(_ context.Context, postID string) ([]CommentData, error) {
+	s.mu.RLock()
+	comments := make([]CommentData, 0, len(s.comments[postID]))
+	for _, comment := range s.comments[postID] {
+		comments = append(comments, comment)
+	}
+	s.mu.RUnlock()
+
+	sortComments(comments)
+	return comments, nil
+}
+
+// RecordEngagementSnapshot stores a sample of the reaction and comment totals of a post
+func (s *MemoryPostStore) RecordEngagementSnapshot(_ context.Context, snapshot EngagementSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.engagementSnapshots[snapshot.FacebookID] = append(s.engagementSnapshots[snapshot.FacebookID], snapshot)
+	return nil
+}
+
+// ListEngagementSnapshots returns the engagement samples of a post, oldest first
+func (s *MemoryPostStore) ListEngagementSnapshots(_ context.Context, facebookID string) ([]EngagementSnapshot, error) {
+	s.mu.RLock()
+	snapshots := make([]EngagementSnapshot, len(s.engagementSnapshots[facebookID]))
+	copy(snapshots, s.engagementSnapshots[facebookID])
+	s.mu.RUnlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].SampledAt.Before(snapshots[j].SampledAt)
+	})
+	return snapshots, nil
+}
+
+// GetBackfillState returns the progress of the last backfill
+func (s *MemoryPostStore) GetBackfillState(_ context.Context) (BackfillState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.backfillState == nil {
+		return BackfillState{}, ErrBackfillNotFound
+	}
+	return *s.backfillState, nil
+}
+
+// SaveBackfillState persists the progress of the running backfill
+func (s *MemoryPostStore) SaveBackfillState(_ context.Context, state BackfillState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backfillState = &state
+	return nil
+}
+
+// UpdateOrInsertWebhookDelivery stores the delivery state of a post for a subscriber
+func (s *MemoryPostStore) UpdateOrInsertWebhookDelivery(_ context.Context, delivery WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.webhookDeliveries[delivery.FacebookID] == nil {
+		s.webhookDeliveries[delivery.FacebookID] = map[string]WebhookDelivery{}
+	}
+	s.webhookDeliveries[delivery.FacebookID][delivery.Subscriber] = delivery
+	return nil
+}
+
+// UpdateWebhookDelivery stores the delivery state of a post for a subscriber, unless it changed since it was read
+func (s *MemoryPostStore) UpdateWebhookDelivery(_ context.Context, delivery WebhookDelivery, readUpdatedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.webhookDeliveries[delivery.FacebookID] == nil {
+		s.webhookDeliveries[delivery.FacebookID] = map[string]WebhookDelivery{}
+	}
+	if stored, ok := s.webhookDeliveries[delivery.FacebookID][delivery.Subscriber]; ok && !stored.UpdatedAt.Equal(readUpdatedAt) {
+		return ErrWebhookDeliveryChanged
+	}
+	s.webhookDeliveries[delivery.FacebookID][delivery.Subscriber] = delivery
+	return nil
+}
+
+// ListWebhookDeliveries returns the delivery state of a post for every subscriber
+func (s *MemoryPostStore) ListWebhookDeliveries(_ context.Context, facebookID string) ([]WebhookDelivery, error) {
+	s.mu.RLock()
+	deliveries := make([]WebhookDelivery, 0, len(s.webhookDeliveries[facebookID]))
+	for _, delivery := range s.webhookDeliveries[facebookID] {
+		deliveries = append(deliveries, delivery)
+	}
+	s.mu.RUnlock()
+
+	sortWebhookDeliveries(deliveries)
+	return deliveries, nil
+}
+
+// QueryWebhookDeliveries calls fn with every delivery in the given status, oldest first
+func (s *MemoryPostStore) QueryWebhookDeliveries(_ context.Context, status string, fn func(delivery WebhookDelivery) bool) error {
+	s.mu.RLock()
+	var matchingDeliveries []WebhookDelivery
+	for _, postDeliveries := range s.webhookDeliveries {
+		for _, delivery := range postDeliveries {
+			if delivery.Status == status {
+				matchingDeliveries = append(matchingDeliveries, delivery)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	sortWebhookDeliveriesByAge(matchingDeliveries)
+	for _, delivery := range matchingDeliveries {
+		if !fn(delivery) {
+			break
+		}
+	}
+	return nil
+}
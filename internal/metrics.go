@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsPath is where the API server exposes metrics in the Prometheus text format
+const metricsPath = "/metrics"
+
+var (
+	postsFetched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "r2d2_posts_fetched_total",
+		Help: "Posts read from the Graph API, by source: feed, backfill or webhook.",
+	}, []string{"source"})
+	postUpserts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "r2d2_post_upserts_total",
+		Help: "Fetched posts by outcome: stored when new or updated, unchanged, or error.",
+	}, []string{"result"})
+	dispatchAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "r2d2_dispatch_attempts_total",
+		Help: "Posts sent to C-3PO, by result: success or failure.",
+	}, []string{"result"})
+	c3poRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "r2d2_c3po_request_duration_seconds",
+		Help:    "Latency of requests to C-3PO, by endpoint.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"endpoint"})
+	graphAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "r2d2_graph_api_errors_total",
+		Help: "Failed Graph API calls, by Graph API error code, or \"other\" for network and decoding errors.",
+	}, []string{"code"})
+	unparsedPosts = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "r2d2_unparsed_posts",
+		Help: "Posts waiting to be dispatched to C-3PO when the dispatcher last ran, backing off ones included.",
+	})
+	jobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "r2d2_job_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of each scheduled job.",
+	}, []string{"job"})
+)
+
+// countGraphError counts a failed Graph API call. Deleted posts are an expected answer, and aren't counted.
+func countGraphError(err error) {
+	if err == nil || isDeletedPostError(err) {
+		return
+	}
+	code := "other"
+	var fbError *fb.Error
+	if errors.As(err, &fbError) {
+		code = strconv.Itoa(fbError.Code)
+	}
+	graphAPIErrors.WithLabelValues(code).Inc()
+}
+
+// countDispatch counts an attempt at sending a post to C-3PO
+func countDispatch(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	dispatchAttempts.WithLabelValues(result).Inc()
+}
+
+// serveMetrics serves the metrics of the default Prometheus registry on metricsPath, and everything else with next
+func serveMetrics(next http.Handler) http.Handler {
+	metricsHandler := promhttp.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsPath {
+			metricsHandler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
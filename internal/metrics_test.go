@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lttkgp/R2-D2/pkg/fakegraph"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPipelineMetrics(t *testing.T) {
+	// Metrics are shared by all tests, so only their change is checked
+	counters := map[string]func() float64{
+		"stored":       func() float64 { return testutil.ToFloat64(postUpserts.WithLabelValues("stored")) },
+		"fetched":      func() float64 { return testutil.ToFloat64(postsFetched.WithLabelValues("feed")) },
+		"succeeded":    func() float64 { return testutil.ToFloat64(dispatchAttempts.WithLabelValues("success")) },
+		"failed":       func() float64 { return testutil.ToFloat64(dispatchAttempts.WithLabelValues("failure")) },
+		"rate limited": func() float64 { return testutil.ToFloat64(graphAPIErrors.WithLabelValues("4")) },
+	}
+	before := map[string]float64{}
+	for name, counter := range counters {
+		before[name] = counter()
+	}
+
+	retryGraphWithoutWaiting(t, 1)
+	p := newTestPipeline(t, 2)
+	p.graph.FailNext("/feed", 1, fakegraph.RateLimitError())
+	failPostOnce(p.c3po, fbGroupID+"_1")
+	p.fetch()
+	p.dispatch()
+
+	expected := map[string]float64{"stored": 2, "fetched": 2, "succeeded": 1, "failed": 1, "rate limited": 1}
+	for name, counter := range counters {
+		if change := counter() - before[name]; change != expected[name] {
+			t.Errorf("Expected %s to grow by %v, got %v", name, expected[name], change)
+		}
+	}
+	if backlog := testutil.ToFloat64(unparsedPosts); backlog != 2 {
+		t.Errorf("Expected a backlog of 2 posts before the dispatch, got %v", backlog)
+	}
+
+	server := httptest.NewServer(serveMetrics(http.NotFoundHandler()))
+	t.Cleanup(server.Close)
+	resp, err := http.Get(server.URL + metricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), `r2d2_c3po_request_duration_seconds_count{endpoint="/v1/data/post"}`) {
+		t.Errorf("Expected the C-3PO latency to be exposed, got %s", body)
+	}
+	if status := getJSON(t, server.URL+"/v1/posts", nil); status != http.StatusNotFound {
+		t.Errorf("Expected other paths to reach the API, got %d", status)
+	}
+}
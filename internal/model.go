@@ -22,28 +22,150 @@ func (k KeyMetadata) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	return nil
 }
 
+// DispatchRecord describes one attempt at sending a post to C-3PO
+type DispatchRecord struct {
+	DispatchedAt time.Time `json:"dispatched_at"`
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+}
+
 // PostData describes the data to be inserted into DB
 type PostData struct {
-	CreatedTime  time.Time `json:"created_time"`
-	FacebookID   string    `json:"facebook_id"`
-	FacebookPost fb.Result `json:"post"`
-	IsParsed     string    `json:"is_parsed"`
+	CreatedTime     time.Time        `json:"created_time"`
+	FacebookID      string           `json:"facebook_id"`
+	FacebookPost    fb.Result        `json:"post"`
+	UpdatedTime     time.Time        `json:"updated_time"`
+	DispatchHistory []DispatchRecord `json:"dispatch_history,omitempty"`
+	// DeletedTime is set once the post is found to be deleted from the group
+	DeletedTime *time.Time `json:"deleted_time,omitempty"`
+	// Links are the music links shared in the post and its comments
+	Links []MusicLink `json:"links,omitempty"`
 }
 
 // MarshalLogObject for PostData type
 func (p PostData) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddTime("created_time", p.CreatedTime)
 	encoder.AddString("facebook_id", p.FacebookID)
-	encoder.AddString("is_parsed", p.IsParsed)
+	encoder.AddTime("updated_time", p.UpdatedTime)
 	return nil
 }
 
+// MusicLink is a normalized link to a song, album, playlist or artist on a music provider
+type MusicLink struct {
+	Provider string `json:"provider"`
+	// URL is the canonical form of the link, without tracking parameters
+	URL string `json:"url"`
+	// ID identifies the linked object within the provider, when the link carries one
+	ID string `json:"id,omitempty"`
+	// Source is where the link was found: link, message or comment
+	Source string `json:"source"`
+}
+
+// CommentMetadata describes the important fields to extract from a comment returned by the Graph API
+type CommentMetadata struct {
+	CreatedTime time.Time `json:"created_time"`
+	FacebookID  string    `json:"id"`
+	Parent      struct {
+		FacebookID string `json:"id"`
+	} `json:"parent"`
+}
+
+// CommentData describes a comment of a post, replies included, to be inserted into DB
+type CommentData struct {
+	PostID          string    `json:"post_id"`
+	FacebookID      string    `json:"facebook_id"`
+	ParentID        string    `json:"parent_id,omitempty"`
+	CreatedTime     time.Time `json:"created_time"`
+	FacebookComment fb.Result `json:"comment"`
+}
+
+// EngagementSnapshot is the reaction and comment totals of a post at a point in time
+type EngagementSnapshot struct {
+	FacebookID string    `json:"facebook_id"`
+	SampledAt  time.Time `json:"sampled_at"`
+	Reactions  int64     `json:"reactions"`
+	Comments   int64     `json:"comments"`
+}
+
+// BackfillState is the progress of a historical feed backfill, persisted after every page so that it can resume
+type BackfillState struct {
+	Since time.Time `json:"since"`
+	// NextPage is the query string of the next feed page, stripped of the access token
+	NextPage     string    `json:"next_page"`
+	PostsFetched int       `json:"posts_fetched"`
+	Done         bool      `json:"done"`
+	Error        string    `json:"error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery tracks the delivery of the latest event of a post to a subscriber, C-3PO included
+type WebhookDelivery struct {
+	FacebookID string `json:"facebook_id"`
+	Subscriber string `json:"subscriber"`
+	Event      string `json:"event"`
+	// Status is pending until the event is delivered, or failed once the subscriber ran out of attempts
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	NextAttemptTime *time.Time `json:"next_attempt_time,omitempty"`
+	// DeliveredAt is the last time an event of the post reached the subscriber
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// FacebookWebhookNotification describes the body of a Facebook Webhooks notification
+type FacebookWebhookNotification struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		// ID is the group the changes belong to
+		ID      string                  `json:"id"`
+		Time    int64                   `json:"time"`
+		Changes []FacebookWebhookChange `json:"changes"`
+	} `json:"entry"`
+}
+
+// FacebookWebhookChange describes a change of the group feed
+type FacebookWebhookChange struct {
+	Field string `json:"field"`
+	Value struct {
+		// Item is the kind of object that changed, e.g. post, status, photo, comment or reaction
+		Item   string `json:"item"`
+		PostID string `json:"post_id"`
+		// Verb is add, edited or remove
+		Verb string `json:"verb"`
+	} `json:"value"`
+}
+
 // C3poRequest describes the request body sent to C-3PO
 type C3poRequest struct {
-	FacebookPost fb.Result `json:"facebook_post"`
+	// FacebookID identifies the post in batch responses
+	FacebookID   string      `json:"facebook_id,omitempty"`
+	FacebookPost fb.Result   `json:"facebook_post"`
+	Comments     []fb.Result `json:"comments"`
+	Links        []MusicLink `json:"links"`
+}
+
+// C3poBatchRequest describes the request body sent to the C-3PO batch endpoint
+type C3poBatchRequest struct {
+	Posts []C3poRequest `json:"posts"`
+}
+
+// C3poItemResponse describes the outcome of a single post of a batch
+type C3poItemResponse struct {
+	FacebookID string `json:"facebook_id"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
+
+// C3poDeleteRequest describes the request body sent to C-3PO when a post is deleted
+type C3poDeleteRequest struct {
+	FacebookID  string    `json:"facebook_id"`
+	DeletedTime time.Time `json:"deleted_time"`
 }
 
 // C3poResponse describes the response from C-3PO POST request
 type C3poResponse struct {
 	Success bool `json:"success"`
+	// Results has an entry per post of a batch request
+	Results []C3poItemResponse `json:"results,omitempty"`
 }
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lttkgp/R2-D2/pkg/fakec3po"
+	"github.com/lttkgp/R2-D2/pkg/fakegraph"
+	"github.com/lttkgp/R2-D2/pkg/signature"
+	"go.uber.org/zap"
+)
+
+// testPipeline runs the fetch → store → dispatch pipeline against a fake Graph API, a fake C-3PO and an in-memory
+// store
+type testPipeline struct {
+	t     *testing.T
+	graph *fakegraph.Server
+	c3po  *fakec3po.Server
+	store *MemoryPostStore
+}
+
+// newTestPipeline serves a feed with a page of posts per entry of pageSizes to a signing C-3PO stand-in
+func newTestPipeline(t *testing.T, pageSizes ...int) *testPipeline {
+	graph := fakegraph.New()
+	t.Cleanup(graph.Close)
+	addTestFeedPages(graph, pageSizes...)
+
+	c3po := fakec3po.New()
+	t.Cleanup(c3po.Close)
+	c3po.RequireSignature([]byte("pipeline"))
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = c3po.URL
+		c.C3POSigningSecret = "pipeline"
+	})
+
+	return &testPipeline{t: t, graph: graph, c3po: c3po, store: NewMemoryPostStore()}
+}
+
+func (p *testPipeline) fetch() {
+	if err := fetchFeed(context.Background(), p.graph.Session(), p.store, zap.NewNop()); err != nil {
+		p.t.Fatalf("Fetching the feed failed: %v", err)
+	}
+}
+
+func (p *testPipeline) dispatch() {
+	if err := NewDispatcher(p.store, zap.NewNop()).Run(context.Background()); err != nil {
+		p.t.Fatalf("Dispatching failed: %v", err)
+	}
+}
+
+// skipRetryBackoffs makes every failed delivery due again, as if its backoff had passed
+func (p *testPipeline) skipRetryBackoffs() {
+	_ = p.store.QueryWebhookDeliveries(context.Background(), webhookDeliveryPending, func(delivery WebhookDelivery) bool {
+		delivery.NextAttemptTime = nil
+		if err := p.store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+			p.t.Fatal(err)
+		}
+		return true
+	})
+}
+
+// deliveryState returns the status of the C-3PO delivery of every stored post
+func (p *testPipeline) deliveryState() map[string]string {
+	page, err := p.store.ListPosts(context.Background(), PostQuery{Limit: 100})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	deliveryState := map[string]string{}
+	for _, postData := range page.Posts {
+		deliveryState[postData.FacebookID] = c3poDeliveryOf(p.t, p.store, postData.FacebookID).Status
+	}
+	return deliveryState
+}
+
+// failPostOnce makes C-3PO reject the first delivery of a post
+func failPostOnce(c3po *fakec3po.Server, facebookID string) {
+	var mu sync.Mutex
+	failed := false
+	c3po.Respond(func(post fakec3po.Post) fakec3po.Result {
+		mu.Lock()
+		defer mu.Unlock()
+		if post.ID() == facebookID && !failed {
+			failed = true
+			return fakec3po.Result{Success: false, Error: "no song found"}
+		}
+		return fakec3po.Result{Success: true}
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	p := newTestPipeline(t, 2, 1)
+	failing := fmt.Sprintf("%s_2", fbGroupID)
+	failPostOnce(p.c3po, failing)
+
+	p.fetch()
+	p.dispatch()
+	if len(p.c3po.Posts()) != 3 {
+		t.Fatalf("Expected every fetched post to be sent to C-3PO, got %d", len(p.c3po.Posts()))
+	}
+	for facebookID, status := range p.deliveryState() {
+		if expected := map[bool]string{true: webhookDeliveryPending, false: webhookDeliveryDelivered}[facebookID == failing]; status != expected {
+			t.Errorf("Expected the delivery of %s to be %s, got %s", facebookID, expected, status)
+		}
+	}
+	if requests := p.c3po.Requests(); requests[0].Header.Get(signature.SignatureHeader) == "" {
+		t.Errorf("Expected requests to C-3PO to be signed, got headers %v", requests[0].Header)
+	}
+
+	// The rejected post backs off before it's sent again
+	p.dispatch()
+	if len(p.c3po.Posts()) != 3 {
+		t.Errorf("Expected no dispatch while the failed post backs off, got %d posts", len(p.c3po.Posts()))
+	}
+	p.skipRetryBackoffs()
+	p.dispatch()
+	posts := p.c3po.Posts()
+	if len(posts) != 4 || posts[3].ID() != failing {
+		t.Fatalf("Expected the failed post to be sent again, got %+v", posts)
+	}
+	if status := p.deliveryState()[failing]; status != webhookDeliveryDelivered {
+		t.Errorf("Expected the post to be delivered once C-3PO accepted it, got %s", status)
+	}
+	postData, _ := p.store.GetPost(context.Background(), failing)
+	if len(postData.DispatchHistory) != 2 || postData.DispatchHistory[0].Success || !postData.DispatchHistory[1].Success {
+		t.Errorf("Expected a failed then a successful dispatch, got %+v", postData.DispatchHistory)
+	}
+
+	// Fetching unchanged posts again doesn't send them again
+	p.fetch()
+	p.dispatch()
+	if len(p.c3po.Posts()) != 4 {
+		t.Errorf("Expected unchanged posts to stay delivered, got %d posts sent", len(p.c3po.Posts()))
+	}
+}
+
+func TestPipelineBatches(t *testing.T) {
+	setTestConfig(t, func(c *Config) { c.DispatcherBatchSize = 3 })
+
+	testCases := []struct {
+		name            string
+		setup           func(c3po *fakec3po.Server)
+		expectedSent    int
+		expectedPending int
+	}{
+		{"batch accepted", func(c3po *fakec3po.Server) {}, 3, 0},
+		{"post of batch rejected", func(c3po *fakec3po.Server) { failPostOnce(c3po, fbGroupID+"_1") }, 3, 1},
+		{"batch unsupported", func(c3po *fakec3po.Server) { c3po.DisableBatch() }, 3, 0},
+		{"C-3PO down", func(c3po *fakec3po.Server) { c3po.FailNext(1, 503) }, 0, 3},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := newTestPipeline(t, 3)
+			testCase.setup(p.c3po)
+
+			p.fetch()
+			p.dispatch()
+			if sent := len(p.c3po.Posts()); sent != testCase.expectedSent {
+				t.Errorf("Expected C-3PO to receive %d posts, got %d", testCase.expectedSent, sent)
+			}
+			pending := 0
+			for _, status := range p.deliveryState() {
+				if status == webhookDeliveryPending {
+					pending++
+				}
+			}
+			if pending != testCase.expectedPending {
+				t.Errorf("Expected %d posts left pending, got %d", testCase.expectedPending, pending)
+			}
+
+			// Whatever failed goes through on the next try
+			p.skipRetryBackoffs()
+			p.dispatch()
+			for facebookID, status := range p.deliveryState() {
+				if status != webhookDeliveryDelivered {
+					t.Errorf("Expected %s to be delivered after a retry, got %s", facebookID, status)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineDeletedPosts(t *testing.T) {
+	p := newTestPipeline(t, 2)
+	p.fetch()
+	p.dispatch()
+	p.graph.DeletePost(fbGroupID + "_1")
+
+	if err := reconcilePosts(context.Background(), p.graph.Session(), p.store, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.c3po.Deletions()) != 0 {
+		t.Errorf("Expected the deletion to wait for the dispatcher, got %+v", p.c3po.Deletions())
+	}
+	p.dispatch()
+	if deletions := p.c3po.Deletions(); len(deletions) != 1 || deletions[0].FacebookID != fbGroupID+"_1" {
+		t.Errorf("Expected C-3PO to be told about the deleted post, got %+v", deletions)
+	}
+	if delivery := c3poDeliveryOf(t, p.store, fbGroupID+"_1"); delivery.Event != webhookEventDeleted || delivery.Status != webhookDeliveryDelivered {
+		t.Errorf("Expected the deletion to be delivered, got %+v", delivery)
+	}
+	if postData, _ := p.store.GetPost(context.Background(), fbGroupID+"_2"); postData.DeletedTime != nil {
+		t.Errorf("Expected the remaining post to be kept, got %+v", postData)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"go.uber.org/zap"
+)
+
+// errAllPostsDeleted is returned when every post checked by a reconciliation looks deleted, which more likely means
+// that the app lost access to the group than that the group was emptied
+var errAllPostsDeleted = errors.New("every checked post looks deleted")
+
+// isDeletedPostError reports whether a Graph API error means that the requested post doesn't exist anymore
+func isDeletedPostError(err error) bool {
+	var fbError *fb.Error
+	// Code 100 with subcode 33 is "Object with ID does not exist, cannot be loaded due to missing permissions, ..."
+	return errors.As(err, &fbError) && fbError.Code == 100 && fbError.ErrorSubcode == 33
+}
+
+// ReconcileDeletedPosts checks the posts created in the last RECONCILE_WINDOW_DAYS days against the Graph API.
+// Graph API calls are cancelled along with ctx.
+func ReconcileDeletedPosts(ctx context.Context, store PostStore, logger *zap.Logger) error {
+	fbSession, err := getFacebookSession(logger)
+	if err != nil {
+		logger.Error("Unable to create Facebook session", zap.Error(err))
+		return err
+	}
+	logger.Debug("Created Facebook session", zap.Any("fbSession", fbSession))
+
+	ctx, span := startSpan(ctx, "ReconcileDeletedPosts")
+	err = reconcilePosts(ctx, fbSession.WithContext(ctx), store, time.Now().AddDate(0, 0, -config.ReconcileWindowDays), logger)
+	endSpan(span, err)
+	return err
+}
+
+// tombstonePost marks a post as deleted from the group now, and queues the deletion for C-3PO and webhook subscribers
+func tombstonePost(ctx context.Context, store PostStore, postData PostData, logger *zap.Logger) error {
+	deletedTime := time.Now().UTC()
+	postData.DeletedTime = &deletedTime
+	if err := store.MarkPostAsDeleted(ctx, postData); err != nil {
+		return err
+	}
+	logger.Info("Post was deleted from Facebook", zap.String("FacebookID", postData.FacebookID))
+	queueWebhookEvent(ctx, store, postData.FacebookID, webhookEventDeleted, logger)
+	return nil
+}
+
+// reconcilePosts tombstones the stored posts created after createdAfter that were deleted from the group. Since a post
+// that can't be read for missing permissions looks deleted too, nothing is tombstoned unless the group feed can be read
+// and some of the checked posts are still there.
+func reconcilePosts(ctx context.Context, fbSession *fb.Session, store PostStore, createdAfter time.Time, logger *zap.Logger) error {
+	_, err := fbSession.Get(fbGroupID, fb.Params{"fields": "id"})
+	countGraphError(err)
+	if err != nil {
+		logger.Warn("Failed reading the group, skipping reconciliation", zap.Error(err))
+		return err
+	}
+
+	posts, err := listAllPosts(ctx, store, PostQuery{CreatedAfter: createdAfter, Limit: 100})
+	if err != nil {
+		logger.Warn("Failed listing posts for reconciliation", zap.Error(err))
+		return err
+	}
+
+	checkedCount := 0
+	var deletedPosts []PostData
+	for _, postData := range posts {
+		// Posts left unchecked are checked on the next run
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if postData.DeletedTime != nil {
+			continue
+		}
+		_, err := fbSession.Get(postData.FacebookID, fb.Params{"fields": "id"})
+		countGraphError(err)
+		if err != nil && !isDeletedPostError(err) {
+			logger.Warn("Failed checking post on Facebook", zap.String("FacebookID", postData.FacebookID), zap.Error(err))
+			continue
+		}
+		checkedCount++
+		if err != nil {
+			deletedPosts = append(deletedPosts, postData)
+		}
+	}
+	if checkedCount > 0 && len(deletedPosts) == checkedCount {
+		logger.Warn("Every checked post looks deleted, skipping reconciliation", zap.Int("checked", checkedCount))
+		return errAllPostsDeleted
+	}
+
+	deletedCount := 0
+	for _, postData := range deletedPosts {
+		if err := tombstonePost(ctx, store, postData, logger); err != nil {
+			continue
+		}
+		deletedCount++
+	}
+
+	logger.Info("Reconciled posts", zap.Int("checked", checkedCount), zap.Int("deleted", deletedCount))
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/fakegraph"
+	"go.uber.org/zap"
+)
+
+func TestReconcilePosts(t *testing.T) {
+	deletedIDs := map[string]bool{"1_2": true, "1_3": true}
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		facebookID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if deletedIDs[facebookID] {
+			_, _ = w.Write([]byte(`{"error": {"message": "Unsupported get request.", "type": "GraphMethodException", "code": 100, "error_subcode": 33}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": facebookID})
+	}))
+	t.Cleanup(graph.Close)
+	fbSession := fb.New("app", "secret").Session("token")
+	fbSession.BaseURL = graph.URL + "/"
+
+	var deleteRequests []C3poDeleteRequest
+	c3po := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deleteRequest C3poDeleteRequest
+		_ = json.NewDecoder(r.Body).Decode(&deleteRequest)
+		if r.URL.Path == "/v1/data/post/delete" {
+			deleteRequests = append(deleteRequests, deleteRequest)
+		}
+		_ = json.NewEncoder(w).Encode(C3poResponse{Success: true})
+	}))
+	t.Cleanup(c3po.Close)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = c3po.URL
+		c.Whoami = "test"
+	})
+
+	store := NewMemoryPostStore()
+	for day, facebookID := range []string{"1_1", "1_2", "1_3"} {
+		err := store.UpdateOrInsertPost(context.Background(), PostData{
+			// 1_3 was created before the reconciliation window
+			CreatedTime:  time.Date(2020, 10, 3-day, 0, 0, 0, 0, time.UTC),
+			FacebookID:   facebookID,
+			FacebookPost: fb.Result{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	createdAfter := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+	for run := 0; run < 2; run++ {
+		if err := reconcilePosts(context.Background(), fbSession, store, createdAfter, zap.NewNop()); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewDispatcher(store, zap.NewNop()).Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(deleteRequests) != 1 || deleteRequests[0].FacebookID != "1_2" {
+		t.Errorf("Expected C-3PO to be notified once of 1_2, got %+v", deleteRequests)
+	}
+	deleted, _ := store.GetPost(context.Background(), "1_2")
+	delivery := c3poDeliveryOf(t, store, "1_2")
+	if deleted.DeletedTime == nil || delivery.Event != webhookEventDeleted || delivery.Status != webhookDeliveryDelivered {
+		t.Errorf("Expected 1_2 to be tombstoned and its deletion delivered, got %+v and %+v", deleted, delivery)
+	}
+	for _, facebookID := range []string{"1_1", "1_3"} {
+		if postData, _ := store.GetPost(context.Background(), facebookID); postData.DeletedTime != nil {
+			t.Errorf("Expected %s not to be tombstoned", facebookID)
+		}
+	}
+}
+
+func TestReconcileKeepsPostsOfUnreadableGroup(t *testing.T) {
+	testCases := map[string]struct {
+		lostGroup bool
+	}{
+		"group unreadable": {lostGroup: true},
+		"every post gone":  {lostGroup: false},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			graph := fakegraph.New()
+			t.Cleanup(graph.Close)
+			store := NewMemoryPostStore()
+			for i := 1; i <= 2; i++ {
+				facebookID := fmt.Sprintf("%s_%d", fbGroupID, i)
+				graph.AddFeedPage(fb.Result{"id": facebookID})
+				// Posts of a group the app can't read anymore answer like deleted posts
+				graph.DeletePost(facebookID)
+				err := store.UpdateOrInsertPost(context.Background(), PostData{
+					CreatedTime:  time.Date(2020, 10, 3, 0, 0, 0, 0, time.UTC),
+					FacebookID:   facebookID,
+					FacebookPost: fb.Result{},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if testCase.lostGroup {
+				graph.FailNext(fbGroupID, 1, fakegraph.NotFoundError())
+			}
+
+			createdAfter := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+			if err := reconcilePosts(context.Background(), graph.Session(), store, createdAfter, zap.NewNop()); err == nil {
+				t.Error("Expected the reconciliation to be skipped")
+			}
+			for i := 1; i <= 2; i++ {
+				postData, _ := store.GetPost(context.Background(), fmt.Sprintf("%s_%d", fbGroupID, i))
+				if postData.DeletedTime != nil {
+					t.Errorf("Expected %s not to be tombstoned", postData.FacebookID)
+				}
+			}
+		})
+	}
+}
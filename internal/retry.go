@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+)
+
+// retryDelay is how long to wait before attempting a delivery again after its n-th failed attempt, starting from
+// initialInterval and backing off exponentially
+func retryDelay(initialInterval time.Duration, attempts int) time.Duration {
+	exponentialBackoff := backoff.NewExponentialBackOff()
+	exponentialBackoff.InitialInterval = initialInterval
+	exponentialBackoff.MaxInterval = 6 * time.Hour
+	exponentialBackoff.MaxElapsedTime = 0
+	exponentialBackoff.Reset()
+
+	delay := exponentialBackoff.InitialInterval
+	for i := 0; i < attempts; i++ {
+		delay = exponentialBackoff.NextBackOff()
+	}
+	return delay
+}
+
+// isDeliveryDue reports whether a pending delivery is out of its retry backoff
+func isDeliveryDue(delivery WebhookDelivery, now time.Time) bool {
+	return delivery.NextAttemptTime == nil || !delivery.NextAttemptTime.After(now)
+}
+
+// finishDelivery stores the outcome of an attempt at a delivery. Failed attempts are retried with the backoff of the
+// subscriber, until it runs out of attempts and the delivery is dead-lettered. Attempts cut short by the end of ctx
+// leave the delivery pending as it was, since the subscriber isn't at fault. The outcome is stored even if ctx is
+// done by then, so that delivered events aren't sent again. Events queued since the delivery was read are left
+// pending instead, so that they are sent too.
+func finishDelivery(ctx context.Context, store PostStore, subscriber WebhookSubscriber, delivery WebhookDelivery, deliveryErr error, logger *zap.Logger) error {
+	if deliveryErr != nil && ctx.Err() != nil {
+		return deliveryErr
+	}
+
+	readUpdatedAt := delivery.UpdatedAt
+	now := time.Now().UTC()
+	delivery.UpdatedAt = now
+	delivery.NextAttemptTime = nil
+	if deliveryErr == nil {
+		delivery.Status = webhookDeliveryDelivered
+		delivery.Attempts = 0
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.Status = webhookDeliveryPending
+		delivery.Attempts++
+		delivery.LastError = deliveryErr.Error()
+		if delivery.Attempts >= subscriber.maxAttempts() {
+			delivery.Status = webhookDeliveryFailed
+			logger.Warn("Dead-lettering delivery after too many failed attempts", zap.String("FacebookID", delivery.FacebookID),
+				zap.String("subscriber", delivery.Subscriber), zap.Int("attempts", delivery.Attempts), zap.Error(deliveryErr))
+		} else {
+			nextAttemptTime := now.Add(retryDelay(subscriber.retryInterval(), delivery.Attempts))
+			delivery.NextAttemptTime = &nextAttemptTime
+		}
+	}
+
+	ctx, cancel := detachContext(ctx)
+	defer cancel()
+	if err := store.UpdateWebhookDelivery(ctx, delivery, readUpdatedAt); err == ErrWebhookDeliveryChanged {
+		logger.Info("Delivery changed during the attempt, leaving the newer event pending",
+			zap.String("FacebookID", delivery.FacebookID), zap.String("subscriber", delivery.Subscriber))
+	} else if err != nil {
+		logger.Warn("Failed to update delivery", zap.String("FacebookID", delivery.FacebookID),
+			zap.String("subscriber", delivery.Subscriber), zap.Error(err))
+	}
+	return deliveryErr
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/swagger/server/models"
+	"go.uber.org/zap"
+)
+
+func TestRetryDelay(t *testing.T) {
+	// Delays grow with the attempts, give or take the randomization factor. The fifth delay is at least 151s,
+	// past the range of the first one.
+	first, fifth := retryDelay(time.Minute, 1), retryDelay(time.Minute, 5)
+	if first < 30*time.Second || first > 90*time.Second {
+		t.Errorf("Expected the first retry after about a minute, got %s", first)
+	}
+	if fifth <= first {
+		t.Errorf("Expected the delay to grow, got %s then %s", first, fifth)
+	}
+}
+
+func TestDispatchFailuresDeadLetterPost(t *testing.T) {
+	server := newTestC3po(t, false)
+	setTestConfig(t, func(c *Config) {
+		c.C3POURI = server.URL
+		c.DispatchMaxAttempts = 2
+	})
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			queueTestPost(t, store, PostData{
+				CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+				FacebookID:   "1_1",
+				FacebookPost: fb.Result{"id": "1_1"},
+			})
+
+			_ = dispatchItem(context.Background(), store, c3poDeliveryOf(t, store, "1_1"), zap.NewNop())
+			delivery := c3poDeliveryOf(t, store, "1_1")
+			if delivery.Attempts != 1 || delivery.LastError == "" || delivery.Status != webhookDeliveryPending {
+				t.Errorf("Expected one failed attempt with the post still queued, got %+v", delivery)
+			}
+			if delivery.NextAttemptTime == nil || isDeliveryDue(delivery, time.Now()) {
+				t.Errorf("Expected the post to back off before its next attempt, got %v", delivery.NextAttemptTime)
+			}
+
+			_ = dispatchItem(context.Background(), store, delivery, zap.NewNop())
+			page, err := store.ListPosts(context.Background(), PostQuery{DeadLettered: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if delivery := c3poDeliveryOf(t, store, "1_1"); len(page.Posts) != 1 || delivery.Attempts != 2 || delivery.Status != webhookDeliveryFailed {
+				t.Fatalf("Expected the post to be dead-lettered after 2 attempts, got %+v and %+v", page.Posts, delivery)
+			}
+
+			// Replaying starts over with a fresh attempt count
+			result := replayDelivery(context.Background(), store, NewDispatcher(store, zap.NewNop()), c3poDeliveryOf(t, store, "1_1"), false, zap.NewNop())
+			if result.Status != models.RedispatchResultStatusQueued {
+				t.Errorf("Expected the replayed delivery to be queued, got %+v", result)
+			}
+			if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryPending || delivery.Attempts != 0 || delivery.NextAttemptTime != nil {
+				t.Errorf("Expected replayed post to be queued afresh, got %+v", delivery)
+			}
+		})
+	}
+}
+
+func TestFinishDeliveryKeepsNewerEvent(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			queueTestPost(t, store, PostData{CreatedTime: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), FacebookID: "1_1"})
+			attempted := c3poDeliveryOf(t, store, "1_1")
+
+			// The post is deleted while its new event is being sent
+			time.Sleep(time.Millisecond)
+			queueWebhookEvent(context.Background(), store, "1_1", webhookEventDeleted, zap.NewNop())
+			if err := finishDelivery(context.Background(), store, c3poSubscriber(), attempted, nil, zap.NewNop()); err != nil {
+				t.Fatal(err)
+			}
+			if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryPending || delivery.Event != webhookEventDeleted {
+				t.Errorf("Expected the deleted event to stay pending, got %+v", delivery)
+			}
+
+			// Once read again, the deleted event is finished like any other
+			attempted = c3poDeliveryOf(t, store, "1_1")
+			if err := finishDelivery(context.Background(), store, c3poSubscriber(), attempted, nil, zap.NewNop()); err != nil {
+				t.Fatal(err)
+			}
+			if delivery := c3poDeliveryOf(t, store, "1_1"); delivery.Status != webhookDeliveryDelivered {
+				t.Errorf("Expected the deleted event to be delivered, got %+v", delivery)
+			}
+		})
+	}
+}
+
+func TestFinishDeliveryUsesSubscriberRetrySettings(t *testing.T) {
+	setTestConfig(t, func(c *Config) {
+		c.WebhookMaxAttempts = 3
+		c.WebhookRetryInterval = 60
+	})
+	store := NewMemoryPostStore()
+	delivery := WebhookDelivery{FacebookID: "1_1", Event: webhookEventNew, Status: webhookDeliveryPending}
+	deliveryErr := errors.New("connection refused")
+
+	// Subscribers without retry settings of their own use WEBHOOK_MAX_ATTEMPTS and WEBHOOK_RETRY_INTERVAL
+	delivery.Subscriber = "search"
+	_ = finishDelivery(context.Background(), store, WebhookSubscriber{Name: "search"}, delivery, deliveryErr, zap.NewNop())
+	deliveries, _ := store.ListWebhookDeliveries(context.Background(), "1_1")
+	if len(deliveries) != 1 || deliveries[0].Status != webhookDeliveryPending || deliveries[0].NextAttemptTime == nil ||
+		deliveries[0].NextAttemptTime.Sub(deliveries[0].UpdatedAt) < 30*time.Second {
+		t.Errorf("Expected the delivery to back off for about a minute, got %+v", deliveries)
+	}
+
+	delivery.Subscriber = "impatient"
+	subscriber := WebhookSubscriber{Name: "impatient", MaxAttempts: 2, RetryInterval: 1}
+	_ = finishDelivery(context.Background(), store, subscriber, delivery, deliveryErr, zap.NewNop())
+	deliveries, _ = store.ListWebhookDeliveries(context.Background(), "1_1")
+	if deliveries[0].Subscriber != "impatient" || deliveries[0].NextAttemptTime == nil ||
+		deliveries[0].NextAttemptTime.Sub(deliveries[0].UpdatedAt) > 2*time.Second {
+		t.Errorf("Expected the delivery to back off for about a second, got %+v", deliveries[0])
+	}
+	_ = finishDelivery(context.Background(), store, subscriber, deliveries[0], deliveryErr, zap.NewNop())
+	deliveries, _ = store.ListWebhookDeliveries(context.Background(), "1_1")
+	if deliveries[0].Status != webhookDeliveryFailed || deliveries[0].Attempts != 2 {
+		t.Errorf("Expected the delivery to be dead-lettered after 2 attempts, got %+v", deliveries[0])
+	}
+}
@@ -1,44 +1,96 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
-	"github.com/aws/aws-sdk-go/service/dynamodb"
+	flags "github.com/jessevdk/go-flags"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
-func scheduleJobs(dynamoSession *dynamodb.DynamoDB, logger *zap.Logger) {
-	cronLogger := cron.VerbosePrintfLogger(log.New(os.Stdout, "cron: ", log.LstdFlags))
+// scheduledJob is a task that `serve` runs periodically, and that can be run on its own from the command line
+type scheduledJob struct {
+	// name labels the job in logs and metrics
+	name string
+	// frequency is the number of seconds between runs
+	frequency int
+	run       func(ctx context.Context) error
+}
 
-	// Start the scheduler to fetch latest Facebook posts
-	fbFetchFrequency := GetEnv("FB_FETCH_FREQUENCY", "300")
-	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
-	_, err := c.AddFunc(fmt.Sprintf("@every %ss", fbFetchFrequency), func() {
-		fetchLatestError := FetchLatestPosts(dynamoSession, logger)
-		if fetchLatestError != nil {
-			logger.Error("Fetching latest posts failed", zap.Error(fetchLatestError))
-		}
-	})
-	if err != nil {
-		logger.Fatal("Unable to start FetchLatestPosts scheduler", zap.Error(err))
+// fetchJob fetches the latest Facebook posts
+func fetchJob(store PostStore, logger *zap.Logger) scheduledJob {
+	return scheduledJob{name: "fetch", frequency: config.FBFetchFrequency, run: func(ctx context.Context) error {
+		return FetchLatestPosts(ctx, store, logger)
+	}}
+}
+
+// dispatchJob dispatches posts to C-3PO with the dispatcher of the process
+func dispatchJob(dispatcher *Dispatcher) scheduledJob {
+	return scheduledJob{name: "dispatch", frequency: config.DispatcherFrequency, run: dispatcher.Run}
+}
+
+// reconcileJob detects posts deleted from the group
+func reconcileJob(store PostStore, logger *zap.Logger) scheduledJob {
+	return scheduledJob{name: "reconcile", frequency: config.ReconcileFrequency, run: func(ctx context.Context) error {
+		return ReconcileDeletedPosts(ctx, store, logger)
+	}}
+}
+
+// webhooksJob delivers post events to webhook subscribers
+func webhooksJob(store PostStore, logger *zap.Logger) scheduledJob {
+	return scheduledJob{name: "webhooks", frequency: config.WebhookDeliveryFrequency, run: func(ctx context.Context) error {
+		return DeliverWebhooks(ctx, store, logger)
+	}}
+}
+
+// runJob runs a job once, giving up after JOB_TIMEOUT seconds, and records its last success
+func runJob(ctx context.Context, job scheduledJob, logger *zap.Logger) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.JobTimeout)*time.Second)
+	defer cancel()
+	if err := job.run(ctx); err != nil {
+		logger.Error("Job failed", zap.String("job", job.name), zap.Error(err))
+		return err
 	}
+	jobLastSuccess.WithLabelValues(job.name).SetToCurrentTime()
+	return nil
+}
 
-	// Start the scheduler to dispatch posts to C-3PO
-	dispatcherFrequency := GetEnv("DISPATCHER_FREQUENCY", "150")
-	_, err = c.AddFunc(fmt.Sprintf("@every %ss", dispatcherFrequency), func() {
-		dispatchError := DispatchFreshPosts(dynamoSession, logger)
-		if dispatchError != nil {
-			logger.Error("Dispatching fresh posts failed", zap.Error(dispatchError))
+// scheduleJobs starts running the jobs periodically, skipping runs while the previous one of the same job is still
+// going. ctx is cancelled when the process shuts down.
+func scheduleJobs(ctx context.Context, jobs []scheduledJob, logger *zap.Logger) *cron.Cron {
+	cronLogger := cron.VerbosePrintfLogger(log.New(os.Stdout, "cron: ", log.LstdFlags))
+	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cronLogger)))
+	for _, job := range jobs {
+		job := job
+		_, err := c.AddFunc(fmt.Sprintf("@every %ds", job.frequency), func() {
+			_ = runJob(ctx, job, logger)
+		})
+		if err != nil {
+			logger.Fatal("Unable to start scheduler", zap.String("job", job.name), zap.Error(err))
 		}
-	})
-	if err != nil {
-		logger.Fatal("Unable to start DispatchFreshPosts scheduler", zap.Error(err))
 	}
 	c.Start()
+	return c
+}
+
+// stopJobs stops scheduling jobs, cancels the running ones through the context given to scheduleJobs, and waits
+// up to timeout for them to return
+func stopJobs(c *cron.Cron, cancel context.CancelFunc, timeout time.Duration, logger *zap.Logger) {
+	logger.Info("Stopping scheduled jobs")
+	stopped := c.Stop()
+	cancel()
+	select {
+	case <-stopped.Done():
+		logger.Info("Stopped scheduled jobs")
+	case <-time.After(timeout):
+		logger.Warn("Timed out waiting for scheduled jobs to stop", zap.Duration("timeout", timeout))
+	}
 }
 
 func main() {
@@ -51,16 +103,18 @@ func main() {
 		}
 	}()
 
-	// Create a DynamoDB session
-	dynamoSession, err := InitializeDynamoSession(logger)
-	if err != nil {
-		logger.Fatal("Error initializing Dynamo Session", zap.Error(err))
+	// Run the command given on the command line, serving by default
+	app := &cli{logger: logger}
+	if err := app.run(os.Args[1:]); err != nil {
+		var flagsErr *flags.Error
+		if errors.As(err, &flagsErr) {
+			if flagsErr.Type == flags.ErrHelp {
+				fmt.Println(flagsErr.Message)
+				return
+			}
+			fmt.Fprintln(os.Stderr, flagsErr.Message)
+			os.Exit(2)
+		}
+		logger.Fatal("Command failed", zap.Error(err))
 	}
-	logger.Debug("Created dynamoDB session", zap.Any("dynamoSession", dynamoSession))
-
-	// Schedule loggers
-	scheduleJobs(dynamoSession, logger)
-
-	// Start API server
-	initializeAPIServer(logger)
 }
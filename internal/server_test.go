@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestStopJobsWaitsForCancelledJobs(t *testing.T) {
+	started, finished := make(chan struct{}), make(chan struct{})
+	job := scheduledJob{name: "test", frequency: 1, run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(finished)
+		return ctx.Err()
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := scheduleJobs(ctx, []scheduledJob{job}, zap.NewNop())
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the job to be run")
+	}
+
+	stopJobs(c, cancel, 5*time.Second, zap.NewNop())
+	select {
+	case <-finished:
+	default:
+		t.Error("Expected the running job to be cancelled and waited for")
+	}
+}
+
+func TestRunJobGivesUpAfterTimeout(t *testing.T) {
+	setTestConfig(t, func(c *Config) { c.JobTimeout = 1 })
+	job := scheduledJob{name: "test", frequency: 1, run: func(ctx context.Context) error {
+		// A job hung on a request gives up once the run's deadline passes
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	start := time.Now()
+	if err := runJob(context.Background(), job, zap.NewNop()); err != context.DeadlineExceeded {
+		t.Errorf("Expected the job to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the job to time out after JOB_TIMEOUT, took %s", elapsed)
+	}
+}
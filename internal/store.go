@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrPostNotFound is returned when a post is not present in the store
+var ErrPostNotFound = errors.New("post not found")
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrBackfillNotFound is returned when no backfill has been started yet
+var ErrBackfillNotFound = errors.New("backfill not found")
+
+// ErrWebhookDeliveryChanged is returned when a delivery was updated since it was read
+var ErrWebhookDeliveryChanged = errors.New("webhook delivery changed")
+
+// deadLetterValue is the is_parsed value of posts that failed dispatching DISPATCH_MAX_ATTEMPTS times, back when the
+// dispatch state of a post was stored on the post itself. Unparsed posts had "false", and parsed posts didn't have the
+// attribute.
+const deadLetterValue = "dead_letter"
+
+// legacyDispatchState is the dispatch state stored on posts before C-3PO deliveries were tracked along with the
+// webhook deliveries. Stores move it over when they're opened.
+type legacyDispatchState struct {
+	FacebookID        string           `json:"facebook_id"`
+	IsParsed          string           `json:"is_parsed"`
+	DispatchHistory   []DispatchRecord `json:"dispatch_history"`
+	DispatchAttempts  int              `json:"dispatch_attempts"`
+	LastDispatchError string           `json:"last_dispatch_error"`
+	NextDispatchTime  *time.Time       `json:"next_dispatch_time"`
+}
+
+// c3poDelivery converts the dispatch state of a post still queued or dead-lettered to its C-3PO delivery
+func (l legacyDispatchState) c3poDelivery() WebhookDelivery {
+	delivery := WebhookDelivery{
+		FacebookID:      l.FacebookID,
+		Subscriber:      c3poSubscriberName,
+		Event:           webhookEventNew,
+		Status:          webhookDeliveryPending,
+		Attempts:        l.DispatchAttempts,
+		LastError:       l.LastDispatchError,
+		NextAttemptTime: l.NextDispatchTime,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	if l.IsParsed == deadLetterValue {
+		delivery.Status = webhookDeliveryFailed
+		delivery.NextAttemptTime = nil
+	}
+	// A post that reached C-3PO before was queued again because it was edited
+	for i := len(l.DispatchHistory) - 1; i >= 0; i-- {
+		if l.DispatchHistory[i].Success {
+			deliveredAt := l.DispatchHistory[i].DispatchedAt
+			delivery.DeliveredAt = &deliveredAt
+			delivery.Event = webhookEventUpdated
+			break
+		}
+	}
+	return delivery
+}
+
+// defaultPostPageLimit is the page size used when a PostQuery doesn't specify one
+const defaultPostPageLimit = 20
+
+// PostQuery filters and paginates the posts returned by ListPosts
+type PostQuery struct {
+	// CreatedAfter and CreatedBefore bound created_time (inclusive) when non-zero
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// IsParsed restricts results to posts with or without a pending C-3PO delivery when set. Posts whose delivery
+	// failed for good count as neither.
+	IsParsed *bool
+	// DeadLettered restricts results to posts whose C-3PO delivery ran out of attempts
+	DeadLettered bool
+	Limit        int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+}
+
+// PostPage is a page of posts returned by ListPosts
+type PostPage struct {
+	Posts []PostData
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// maxDispatchHistory is the number of latest dispatch attempts kept per post, which keeps posts dispatched over and
+// over below the DynamoDB item size limit
+const maxDispatchHistory = 20
+
+// appendDispatchRecord appends a record to a dispatch history, dropping the oldest attempts beyond
+// maxDispatchHistory. The given history is left unchanged.
+func appendDispatchRecord(history []DispatchRecord, record DispatchRecord) []DispatchRecord {
+	if len(history) >= maxDispatchHistory {
+		history = history[len(history)-maxDispatchHistory+1:]
+	}
+	appended := make([]DispatchRecord, len(history), len(history)+1)
+	copy(appended, history)
+	return append(appended, record)
+}
+
+// PostStore persists Facebook posts and their delivery to C-3PO and the webhook subscribers. Calls to remote backends
+// are cancelled along with the ctx of each method, which local backends ignore.
+type PostStore interface {
+	// UpdateOrInsertPost creates a post, or overwrites it if its updated_time is newer than the stored one.
+	// Unchanged posts are left alone, and overwritten ones keep their dispatch history and tombstone.
+	UpdateOrInsertPost(ctx context.Context, postData PostData) error
+	// MarkPostAsDeleted stores the deleted_time field of the post
+	MarkPostAsDeleted(ctx context.Context, postData PostData) error
+	// RecordDispatch appends an attempt to send the post to C-3PO to its dispatch history, which keeps the latest
+	// maxDispatchHistory attempts
+	RecordDispatch(ctx context.Context, postData PostData, record DispatchRecord) error
+	// GetPost fetches a post by its Facebook ID
+	GetPost(ctx context.Context, facebookID string) (PostData, error)
+	// ListPosts returns a page of posts matching the query
+	ListPosts(ctx context.Context, query PostQuery) (PostPage, error)
+	// UpdateOrInsertComment stores a comment of a post, overwriting any previous version
+	UpdateOrInsertComment(ctx context.Context, comment CommentData) error
+	// DeleteComment removes a stored comment of a post, if any
+	DeleteComment(ctx context.Context, postID string, commentID string) error
+	// ListComments returns the comments of a post, replies included, oldest first
+	ListComments(ctx context.Context, postID string) ([]CommentData, error)
+	// RecordEngagementSnapshot stores a sample of the reaction and comment totals of a post
+	RecordEngagementSnapshot(ctx context.Context, snapshot EngagementSnapshot) error
+	// ListEngagementSnapshots returns the engagement samples of a post, oldest first
+	ListEngagementSnapshots(ctx context.Context, facebookID string) ([]EngagementSnapshot, error)
+	// GetBackfillState returns the progress of the last backfill
+	GetBackfillState(ctx context.Context) (BackfillState, error)
+	// SaveBackfillState persists the progress of the running backfill
+	SaveBackfillState(ctx context.Context, state BackfillState) error
+	// UpdateOrInsertWebhookDelivery stores the delivery state of a post for a subscriber, overwriting any previous one
+	UpdateOrInsertWebhookDelivery(ctx context.Context, delivery WebhookDelivery) error
+	// UpdateWebhookDelivery stores the delivery state of a post for a subscriber, unless the stored one isn't the
+	// version last updated at readUpdatedAt, in which case ErrWebhookDeliveryChanged is returned
+	UpdateWebhookDelivery(ctx context.Context, delivery WebhookDelivery, readUpdatedAt time.Time) error
+	// ListWebhookDeliveries returns the delivery state of a post for every subscriber, ordered by subscriber
+	ListWebhookDeliveries(ctx context.Context, facebookID string) ([]WebhookDelivery, error)
+	// QueryWebhookDeliveries calls fn with every delivery in the given status, oldest first.
+	// Iteration stops early if fn returns false.
+	QueryWebhookDeliveries(ctx context.Context, status string, fn func(delivery WebhookDelivery) bool) error
+}
+
+// InitializeStore creates the PostStore selected by the `STORAGE_BACKEND` setting
+func InitializeStore(ctx context.Context, logger *zap.Logger) (PostStore, error) {
+	switch config.StorageBackend {
+	case "dynamodb":
+		dynamoSession, err := InitializeDynamoSession(ctx, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug("Created dynamoDB session", zap.Any("dynamoSession", dynamoSession))
+		return NewDynamoPostStore(dynamoSession, logger), nil
+	case "bolt":
+		return InitializeBoltStore(config.BoltPath, logger)
+	case "memory":
+		return NewMemoryPostStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.StorageBackend)
+	}
+}
+
+// closeStore releases what the store holds once it's no longer used, such as the lock on the BoltDB file
+func closeStore(store PostStore, logger *zap.Logger) {
+	closer, ok := store.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Warn("Unable to close post store", zap.Error(err))
+	}
+}
+
+// listAllPosts follows the pagination of ListPosts and returns every matching post
+func listAllPosts(ctx context.Context, store PostStore, query PostQuery) ([]PostData, error) {
+	var posts []PostData
+	for {
+		page, err := store.ListPosts(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, page.Posts...)
+		if page.NextCursor == "" {
+			return posts, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// withStoredFields returns a fetched post along with the fields the store keeps of the stored version: its dispatch
+// history and tombstone. DynamoDB leaves the attributes of these fields alone when a post is overwritten.
+func withStoredFields(fetched PostData, stored PostData) PostData {
+	fetched.DispatchHistory = stored.DispatchHistory
+	fetched.DeletedTime = stored.DeletedTime
+	return fetched
+}
+
+// isPostChanged reports whether a fetched post is an edit of the stored one
+func isPostChanged(stored PostData, fetched PostData) bool {
+	return fetched.UpdatedTime.After(stored.UpdatedTime)
+}
+
+// sortComments orders comments oldest first, breaking ties on the Facebook ID
+func sortComments(comments []CommentData) {
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedTime.Equal(comments[j].CreatedTime) {
+			return comments[i].CreatedTime.Before(comments[j].CreatedTime)
+		}
+		return comments[i].FacebookID < comments[j].FacebookID
+	})
+}
+
+// sortWebhookDeliveries orders deliveries by Facebook ID, then subscriber
+func sortWebhookDeliveries(deliveries []WebhookDelivery) {
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].FacebookID != deliveries[j].FacebookID {
+			return deliveries[i].FacebookID < deliveries[j].FacebookID
+		}
+		return deliveries[i].Subscriber < deliveries[j].Subscriber
+	})
+}
+
+// sortWebhookDeliveriesByAge orders deliveries oldest first, breaking ties like sortWebhookDeliveries
+func sortWebhookDeliveriesByAge(deliveries []WebhookDelivery) {
+	sortWebhookDeliveries(deliveries)
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].UpdatedAt.Before(deliveries[j].UpdatedAt)
+	})
+}
+
+// encodeCursor serializes a table key into an opaque pagination cursor
+func encodeCursor(key map[string]string) string {
+	if len(key) == 0 {
+		return ""
+	}
+	jsonKey, err := json.Marshal(key)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(jsonKey)
+}
+
+// decodeCursor parses a cursor created by encodeCursor
+func decodeCursor(cursor string) (map[string]string, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	jsonKey, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var key map[string]string
+	if err := json.Unmarshal(jsonKey, &key); err != nil || key[partitionKey] == "" || key[sortKey] == "" {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
+
+// postKey builds the table key of a post, as used by cursors
+func postKey(postData PostData) map[string]string {
+	return map[string]string{
+		partitionKey: postData.FacebookID,
+		sortKey:      postData.CreatedTime.UTC().Format(time.RFC3339),
+	}
+}
+
+// postKeyBefore orders keys newest first, breaking ties on the Facebook ID
+func postKeyBefore(a, b map[string]string) bool {
+	if a[sortKey] != b[sortKey] {
+		return a[sortKey] > b[sortKey]
+	}
+	return a[partitionKey] > b[partitionKey]
+}
+
+// filtersC3poStatus reports whether a query filters posts on the status of their C-3PO delivery
+func filtersC3poStatus(query PostQuery) bool {
+	return query.IsParsed != nil || query.DeadLettered
+}
+
+// matchesC3poStatus reports whether a post whose C-3PO delivery has the given status passes the filters of a query.
+// Posts without a C-3PO delivery were stored before deliveries were tracked, and count as parsed.
+func matchesC3poStatus(status string, query PostQuery) bool {
+	if query.IsParsed != nil && *query.IsParsed && (status == webhookDeliveryPending || status == webhookDeliveryFailed) {
+		return false
+	}
+	if query.IsParsed != nil && !*query.IsParsed && status != webhookDeliveryPending {
+		return false
+	}
+	if query.DeadLettered && status != webhookDeliveryFailed {
+		return false
+	}
+	return true
+}
+
+// matchesPostQuery reports whether a post passes the created_time filters of a query
+func matchesPostQuery(postData PostData, query PostQuery) bool {
+	if !query.CreatedAfter.IsZero() && postData.CreatedTime.Before(query.CreatedAfter) {
+		return false
+	}
+	if !query.CreatedBefore.IsZero() && postData.CreatedTime.After(query.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// paginatePosts applies a query to a full list of posts, newest first. c3poStatuses holds the status of the C-3PO
+// delivery of every post that has one. Used by stores without native pagination.
+func paginatePosts(posts []PostData, c3poStatuses map[string]string, query PostQuery) (PostPage, error) {
+	cursorKey, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return PostPage{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPostPageLimit
+	}
+
+	var matchingPosts []PostData
+	for _, postData := range posts {
+		if matchesPostQuery(postData, query) && matchesC3poStatus(c3poStatuses[postData.FacebookID], query) &&
+			(cursorKey == nil || postKeyBefore(cursorKey, postKey(postData))) {
+			matchingPosts = append(matchingPosts, postData)
+		}
+	}
+	sort.Slice(matchingPosts, func(i, j int) bool {
+		return postKeyBefore(postKey(matchingPosts[i]), postKey(matchingPosts[j]))
+	})
+
+	if len(matchingPosts) <= query.Limit {
+		return PostPage{Posts: matchingPosts}, nil
+	}
+	matchingPosts = matchingPosts[:query.Limit]
+	return PostPage{
+		Posts:      matchingPosts,
+		NextCursor: encodeCursor(postKey(matchingPosts[len(matchingPosts)-1])),
+	}, nil
+}
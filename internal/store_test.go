@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// testStores returns a fresh instance of every PostStore that runs without external services
+func testStores(t *testing.T) map[string]PostStore {
+	boltStore, err := InitializeBoltStore(filepath.Join(t.TempDir(), "r2d2.db"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = boltStore.Close() })
+
+	return map[string]PostStore{
+		"memory": NewMemoryPostStore(),
+		"bolt":   boltStore,
+	}
+}
+
+// queueTestPost stores a post and queues it for C-3PO and the webhook subscribers, like a fetch does
+func queueTestPost(t *testing.T, store PostStore, postData PostData) {
+	t.Helper()
+	if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+		t.Fatal(err)
+	}
+	queueWebhookEvent(context.Background(), store, postData.FacebookID, webhookEventNew, zap.NewNop())
+}
+
+// c3poDeliveryOf returns the C-3PO delivery of a post, or an empty delivery if it has none
+func c3poDeliveryOf(t *testing.T, store PostStore, facebookID string) WebhookDelivery {
+	t.Helper()
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), facebookID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Subscriber == c3poSubscriberName {
+			return delivery
+		}
+	}
+	return WebhookDelivery{}
+}
+
+func TestPostStore(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testPostStore(t, store)
+		})
+	}
+}
+
+func testPostStore(t *testing.T, store PostStore) {
+	older := PostData{
+		CreatedTime:  time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"message": "first"},
+	}
+	newer := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_2",
+		FacebookPost: fb.Result{"message": "second"},
+	}
+	for _, postData := range []PostData{older, newer} {
+		if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, delivery := range []WebhookDelivery{
+		{FacebookID: "1_1", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryFailed},
+		{FacebookID: "1_2", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryPending},
+		{FacebookID: "1_2", Subscriber: "search", Event: webhookEventNew, Status: webhookDeliveryFailed},
+	} {
+		if err := store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Posts are filtered on their C-3PO delivery only
+	isParsed := false
+	page, err := store.ListPosts(context.Background(), PostQuery{IsParsed: &isParsed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].FacebookID != "1_2" || page.NextCursor != "" {
+		t.Errorf("Expected only unparsed post 1_2, got %+v", page)
+	}
+	page, err = store.ListPosts(context.Background(), PostQuery{DeadLettered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].FacebookID != "1_1" {
+		t.Errorf("Expected only dead-lettered post 1_1, got %+v", page)
+	}
+	_ = store.UpdateOrInsertWebhookDelivery(context.Background(), WebhookDelivery{
+		FacebookID: "1_2", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryDelivered,
+	})
+	isParsed = true
+	page, err = store.ListPosts(context.Background(), PostQuery{IsParsed: &isParsed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].FacebookID != "1_2" {
+		t.Errorf("Expected only parsed post 1_2, got %+v", page)
+	}
+
+	page, err = store.ListPosts(context.Background(), PostQuery{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].FacebookID != "1_2" || page.NextCursor == "" {
+		t.Errorf("Expected first page with newest post and a cursor, got %+v", page)
+	}
+	page, err = store.ListPosts(context.Background(), PostQuery{Limit: 1, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].FacebookID != "1_1" || page.NextCursor != "" {
+		t.Errorf("Expected last page with oldest post, got %+v", page)
+	}
+
+	if _, err := store.GetPost(context.Background(), "missing"); err != ErrPostNotFound {
+		t.Errorf("Expected ErrPostNotFound, got %v", err)
+	}
+
+	if _, err := store.GetBackfillState(context.Background()); err != ErrBackfillNotFound {
+		t.Errorf("Expected ErrBackfillNotFound, got %v", err)
+	}
+	backfillState := BackfillState{Since: older.CreatedTime, NextPage: "after=abc", PostsFetched: 2}
+	if err := store.SaveBackfillState(context.Background(), backfillState); err != nil {
+		t.Fatal(err)
+	}
+	savedState, err := store.GetBackfillState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !savedState.Since.Equal(backfillState.Since) || savedState.NextPage != "after=abc" || savedState.PostsFetched != 2 {
+		t.Errorf("Expected saved backfill state, got %+v", savedState)
+	}
+}
+
+func TestUpdateOrInsertPostDetectsEdits(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			postData := PostData{
+				CreatedTime:  time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+				FacebookID:   "1_1",
+				FacebookPost: fb.Result{"message": "first"},
+				UpdatedTime:  time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+			}
+			if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+				t.Fatal(err)
+			}
+
+			// A post with the same updated_time is the version already stored
+			unchanged := postData
+			unchanged.FacebookPost = fb.Result{"message": "unchanged"}
+			if err := store.UpdateOrInsertPost(context.Background(), unchanged); err != nil {
+				t.Fatal(err)
+			}
+			if stored, _ := store.GetPost(context.Background(), "1_1"); stored.FacebookPost["message"] != "first" {
+				t.Errorf("Expected unchanged post to be left alone, got %+v", stored)
+			}
+
+			edited := postData
+			edited.FacebookPost = fb.Result{"message": "edited"}
+			edited.UpdatedTime = postData.UpdatedTime.Add(time.Hour)
+			if err := store.UpdateOrInsertPost(context.Background(), edited); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := store.GetPost(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.FacebookPost["message"] != "edited" || !stored.UpdatedTime.Equal(edited.UpdatedTime) {
+				t.Errorf("Expected edited post to be overwritten, got %+v", stored)
+			}
+		})
+	}
+}
+
+func TestUpdateOrInsertPostKeepsStoredFields(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			postData := PostData{
+				CreatedTime:  time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+				FacebookID:   "1_1",
+				FacebookPost: fb.Result{"message": "first"},
+				UpdatedTime:  time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+			}
+			if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+				t.Fatal(err)
+			}
+			record := DispatchRecord{DispatchedAt: time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC)}
+			if err := store.RecordDispatch(context.Background(), postData, record); err != nil {
+				t.Fatal(err)
+			}
+			deletedTime := time.Date(2020, 9, 3, 0, 0, 0, 0, time.UTC)
+			tombstoned := postData
+			tombstoned.DeletedTime = &deletedTime
+			if err := store.MarkPostAsDeleted(context.Background(), tombstoned); err != nil {
+				t.Fatal(err)
+			}
+
+			// Fetched posts never carry the fields owned by the store
+			edited := postData
+			edited.FacebookPost = fb.Result{"message": "edited"}
+			edited.UpdatedTime = postData.UpdatedTime.Add(time.Hour)
+			if err := store.UpdateOrInsertPost(context.Background(), edited); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := store.GetPost(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.FacebookPost["message"] != "edited" {
+				t.Errorf("Expected edited post to be overwritten, got %+v", stored)
+			}
+			if stored.DeletedTime == nil || !stored.DeletedTime.Equal(deletedTime) {
+				t.Errorf("Expected tombstone to be kept, got %v", stored.DeletedTime)
+			}
+			if len(stored.DispatchHistory) != 1 || !stored.DispatchHistory[0].DispatchedAt.Equal(record.DispatchedAt) {
+				t.Errorf("Expected dispatch history to be kept, got %+v", stored.DispatchHistory)
+			}
+		})
+	}
+}
+
+func TestListPostsNewestFirst(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// Facebook IDs are out of created_time order, so that key order doesn't pass for it
+			for _, postData := range []PostData{
+				{CreatedTime: time.Date(2020, 10, 3, 0, 0, 0, 0, time.UTC), FacebookID: "1_1"},
+				{CreatedTime: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC), FacebookID: "1_2"},
+				{CreatedTime: time.Date(2020, 11, 5, 0, 0, 0, 0, time.UTC), FacebookID: "1_3"},
+				{CreatedTime: time.Date(2020, 10, 20, 0, 0, 0, 0, time.UTC), FacebookID: "1_4"},
+			} {
+				if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, facebookID := range []string{"1_2", "1_4"} {
+				delivery := WebhookDelivery{FacebookID: facebookID, Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryPending}
+				if err := store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			isParsed := false
+			for _, tc := range []struct {
+				name     string
+				query    PostQuery
+				expected string
+			}{
+				{"all pages", PostQuery{Limit: 3}, "1_3 1_4 1_1 1_2"},
+				{"created window", PostQuery{CreatedAfter: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), CreatedBefore: time.Date(2020, 10, 31, 0, 0, 0, 0, time.UTC)}, "1_4 1_1"},
+				{"unparsed", PostQuery{IsParsed: &isParsed, Limit: 1}, "1_4 1_2"},
+			} {
+				posts, err := listAllPosts(context.Background(), store, tc.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var facebookIDs []string
+				for _, postData := range posts {
+					facebookIDs = append(facebookIDs, postData.FacebookID)
+				}
+				if strings.Join(facebookIDs, " ") != tc.expected {
+					t.Errorf("%s: expected posts %s, got %v", tc.name, tc.expected, facebookIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchHistoryIsCapped(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			postData := PostData{CreatedTime: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), FacebookID: "1_1"}
+			if err := store.UpdateOrInsertPost(context.Background(), postData); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+			for i := 0; i < maxDispatchHistory+5; i++ {
+				record := DispatchRecord{DispatchedAt: start.Add(time.Duration(i) * time.Minute)}
+				if err := store.RecordDispatch(context.Background(), postData, record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stored, err := store.GetPost(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			history := stored.DispatchHistory
+			if len(history) != maxDispatchHistory {
+				t.Fatalf("Expected the latest %d attempts to be kept, got %d", maxDispatchHistory, len(history))
+			}
+			if !history[0].DispatchedAt.Equal(start.Add(5*time.Minute)) || !history[len(history)-1].DispatchedAt.Equal(start.Add(time.Duration(maxDispatchHistory+4)*time.Minute)) {
+				t.Errorf("Expected the oldest attempts to be dropped, got %v to %v", history[0].DispatchedAt, history[len(history)-1].DispatchedAt)
+			}
+		})
+	}
+}
+
+func TestEngagementSnapshots(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			sampledAt := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+			for _, snapshot := range []EngagementSnapshot{
+				{FacebookID: "1_1", SampledAt: sampledAt.Add(time.Hour), Reactions: 5, Comments: 2},
+				{FacebookID: "1_1", SampledAt: sampledAt, Reactions: 3, Comments: 1},
+				{FacebookID: "1_10", SampledAt: sampledAt, Reactions: 9, Comments: 9},
+			} {
+				if err := store.RecordEngagementSnapshot(context.Background(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			snapshots, err := store.ListEngagementSnapshots(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(snapshots) != 2 || snapshots[0].Reactions != 3 || snapshots[1].Reactions != 5 {
+				t.Errorf("Expected the snapshots of 1_1 oldest first, got %+v", snapshots)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			updatedAt := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+			for _, delivery := range []WebhookDelivery{
+				{FacebookID: "1_1", Subscriber: "search", Event: webhookEventNew, Status: webhookDeliveryPending, UpdatedAt: updatedAt.Add(time.Hour)},
+				{FacebookID: "1_1", Subscriber: "discord", Event: webhookEventNew, Status: webhookDeliveryDelivered, UpdatedAt: updatedAt},
+				{FacebookID: "1_10", Subscriber: "search", Event: webhookEventDeleted, Status: webhookDeliveryPending, UpdatedAt: updatedAt},
+				{FacebookID: "1_10", Subscriber: c3poSubscriberName, Event: webhookEventNew, Status: webhookDeliveryFailed, UpdatedAt: updatedAt},
+			} {
+				if err := store.UpdateOrInsertWebhookDelivery(context.Background(), delivery); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			deliveries, err := store.ListWebhookDeliveries(context.Background(), "1_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deliveries) != 2 || deliveries[0].Subscriber != "discord" || deliveries[1].Subscriber != "search" {
+				t.Errorf("Expected the deliveries of 1_1 ordered by subscriber, got %+v", deliveries)
+			}
+
+			var pending []string
+			err = store.QueryWebhookDeliveries(context.Background(), webhookDeliveryPending, func(delivery WebhookDelivery) bool {
+				pending = append(pending, delivery.FacebookID+"/"+delivery.Subscriber)
+				return true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != 2 || pending[0] != "1_10/search" || pending[1] != "1_1/search" {
+				t.Errorf("Expected pending deliveries oldest first, got %v", pending)
+			}
+
+			var failed []string
+			_ = store.QueryWebhookDeliveries(context.Background(), webhookDeliveryFailed, func(delivery WebhookDelivery) bool {
+				failed = append(failed, delivery.FacebookID+"/"+delivery.Subscriber)
+				return true
+			})
+			if len(failed) != 1 || failed[0] != "1_10/"+c3poSubscriberName {
+				t.Errorf("Expected only the failed C-3PO delivery, got %v", failed)
+			}
+		})
+	}
+}
+
+func TestCloseStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r2d2.db")
+	boltStore, err := InitializeBoltStore(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeStore(boltStore, zap.NewNop())
+
+	// The file lock is released, so the next process opens it right away
+	reopened, err := InitializeBoltStore(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Expected the closed BoltDB file to open again, got %v", err)
+	}
+	_ = reopened.Close()
+
+	// Stores without anything to release are left alone
+	closeStore(NewMemoryPostStore(), zap.NewNop())
+}
+
+func TestBoltMigratesParsedIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r2d2.db")
+	legacyDB, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = legacyDB.Update(func(tx *bolt.Tx) error {
+		posts, err := tx.CreateBucket(boltPostsBucket)
+		if err != nil {
+			return err
+		}
+		parsedIndex, err := tx.CreateBucket(boltParsedIndexBucket)
+		if err != nil {
+			return err
+		}
+		for facebookID, value := range map[string]string{
+			"1_1": `{"facebook_id":"1_1","created_time":"2020-10-01T00:00:00Z","is_parsed":"false","dispatch_attempts":2,"last_dispatch_error":"timeout"}`,
+			"1_2": `{"facebook_id":"1_2","created_time":"2020-10-02T00:00:00Z","is_parsed":"dead_letter","dispatch_attempts":5,"dispatch_history":[{"dispatched_at":"2020-10-02T01:00:00Z","success":true}]}`,
+			"1_3": `{"facebook_id":"1_3","created_time":"2020-10-03T00:00:00Z"}`,
+		} {
+			if err := posts.Put([]byte(facebookID), []byte(value)); err != nil {
+				return err
+			}
+			if facebookID != "1_3" {
+				if err := parsedIndex.Put([]byte(facebookID), []byte(facebookID)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	_ = legacyDB.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := InitializeBoltStore(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	queued := c3poDeliveryOf(t, store, "1_1")
+	if queued.Status != webhookDeliveryPending || queued.Event != webhookEventNew || queued.Attempts != 2 || queued.LastError != "timeout" {
+		t.Errorf("Expected the queued post to keep its attempts as a pending delivery, got %+v", queued)
+	}
+	deadLettered := c3poDeliveryOf(t, store, "1_2")
+	if deadLettered.Status != webhookDeliveryFailed || deadLettered.Event != webhookEventUpdated || deadLettered.DeliveredAt == nil {
+		t.Errorf("Expected the dead-lettered post to become a failed update, got %+v", deadLettered)
+	}
+	if parsed := c3poDeliveryOf(t, store, "1_3"); parsed.Status != "" {
+		t.Errorf("Expected parsed posts to be left alone, got %+v", parsed)
+	}
+	_ = store.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltParsedIndexBucket) != nil {
+			t.Errorf("Expected the parsed index to be dropped")
+		}
+		if value := tx.Bucket(boltPostsBucket).Get([]byte("1_1")); strings.Contains(string(value), "is_parsed") {
+			t.Errorf("Expected the dispatch state to be stripped from the post, got %s", value)
+		}
+		return nil
+	})
+}
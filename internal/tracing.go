@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// tracerName identifies the spans started by R2-D2
+const tracerName = "github.com/lttkgp/R2-D2"
+
+// initTracing sets up the export of spans configured by the `TRACING_EXPORTER` setting: "stdout", "otlp",
+// or nothing to disable tracing. W3C trace context is propagated to C-3PO either way. The returned function flushes
+// the pending spans and stops the exporter.
+func initTracing(logger *zap.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter exporttrace.SpanExporter
+	switch config.TracingExporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		stdoutExporter, err := stdout.NewExporter(stdout.WithWriter(os.Stdout), stdout.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		exporter = stdoutExporter
+	case "otlp":
+		options := []otlpgrpc.Option{otlpgrpc.WithEndpoint(config.OTLPEndpoint)}
+		if config.OTLPInsecure {
+			options = append(options, otlpgrpc.WithInsecure())
+		}
+		otlpExporter, err := otlp.NewExporter(context.Background(), otlpgrpc.NewDriver(options...))
+		if err != nil {
+			return nil, err
+		}
+		exporter = otlpExporter
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected stdout or otlp", config.TracingExporter)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String("r2-d2"))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	logger.Info("Exporting traces", zap.String("exporter", config.TracingExporter))
+	return tracerProvider.Shutdown, nil
+}
+
+// startSpan starts a span as a child of the one in ctx, if any
+func startSpan(ctx context.Context, name string, opts ...trace.SpanOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endSpan records the outcome of the operation traced by span, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// injectTraceContext adds the W3C trace context of the span in ctx to outgoing request headers
+func injectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, header)
+}
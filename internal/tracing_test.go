@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPipelineTracing(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	otel.SetTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	// The global defaults can't be installed again, so tracing is left disabled instead
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	p := newTestPipeline(t, 1, 1)
+	p.fetch()
+	p.dispatch()
+
+	spans := map[string][]*oteltest.Span{}
+	for _, span := range spanRecorder.Completed() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	expected := map[string]int{"feed page": 2, "UpdateOrInsertPost": 2, "DispatchFreshPosts": 1, "POST /v1/data/post": 2}
+	for name, count := range expected {
+		if len(spans[name]) != count {
+			t.Errorf("Expected %d %q spans, got %d", count, name, len(spans[name]))
+		}
+	}
+	if len(spans["DispatchFreshPosts"]) == 0 || len(spans["POST /v1/data/post"]) == 0 {
+		t.FailNow()
+	}
+
+	// Requests to C-3PO belong to the trace of the dispatcher run, and carry it along
+	traceID := spans["DispatchFreshPosts"][0].SpanContext().TraceID
+	requestSpan := spans["POST /v1/data/post"][0]
+	if requestSpan.SpanContext().TraceID != traceID {
+		t.Errorf("Expected the C-3PO request to be traced as part of the dispatcher run")
+	}
+	if kind := requestSpan.SpanKind(); kind != trace.SpanKindClient {
+		t.Errorf("Expected the C-3PO request to be a client span, got %v", kind)
+	}
+	for _, request := range p.c3po.Requests() {
+		if traceparent := request.Header.Get("traceparent"); !strings.Contains(traceparent, traceID.String()) {
+			t.Errorf("Expected C-3PO to receive trace %s, got traceparent %q", traceID, traceparent)
+		}
+	}
+}
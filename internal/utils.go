@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// envFile is the file env variables are loaded from on startup
+const envFile = "./.env"
+
 // fileExists returns true if  filename is a valid file
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
@@ -17,7 +23,6 @@ func fileExists(filename string) bool {
 
 // updateEnvFile writes the key=value pair to a .env file if it exists
 func updateEnvFile(key string, value string) {
-	envFile := "./.env"
 	if fileExists(envFile) {
 		envMap, err := godotenv.Read(envFile)
 		if err != nil {
@@ -31,3 +36,30 @@ func updateEnvFile(key string, value string) {
 		}
 	}
 }
+
+// requestTimeout bounds every request to the Graph API, C-3PO, DynamoDB and webhook subscribers
+func requestTimeout() time.Duration {
+	return time.Duration(config.RequestTimeout) * time.Second
+}
+
+// newHTTPClient creates a client whose requests give up after REQUEST_TIMEOUT seconds, on top of the deadline of
+// their context
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout()}
+}
+
+// detachedContext keeps the values of its parent, such as the current span, but none of its deadline or
+// cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// detachContext returns a context to record the outcome of work done with ctx, even once ctx is cancelled, e.g.
+// that C-3PO accepted a post. It is only bound by REQUEST_TIMEOUT.
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(detachedContext{ctx}, requestTimeout())
+}
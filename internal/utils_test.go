@@ -1,7 +1,28 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestUtils(t *testing.T) {
 	t.Logf("This is a dummy test")
 }
+
+func TestDetachContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "span"))
+	cancel()
+
+	ctx, cancelDetached := detachContext(parent)
+	defer cancelDetached()
+	if ctx.Err() != nil {
+		t.Errorf("Expected the detached context to outlive its parent, got %v", ctx.Err())
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("Expected the detached context to be bound by REQUEST_TIMEOUT")
+	}
+	if ctx.Value(key{}) != "span" {
+		t.Error("Expected the detached context to keep the values of its parent")
+	}
+}
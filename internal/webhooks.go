@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/signature"
+	"go.uber.org/zap"
+)
+
+// Events sent to webhook subscribers
+const (
+	webhookEventNew     = "new"
+	webhookEventUpdated = "updated"
+	webhookEventDeleted = "deleted"
+)
+
+// Statuses of a webhook delivery
+const (
+	webhookDeliveryPending   = "pending"
+	webhookDeliveryDelivered = "delivered"
+	webhookDeliveryFailed    = "failed"
+)
+
+// c3poSubscriberName is the name C-3PO's deliveries are stored under next to those of the webhook subscribers
+const c3poSubscriberName = "c3po"
+
+// errUnknownWebhookSubscriber is recorded for pending deliveries of a subscriber that was removed from the config
+var errUnknownWebhookSubscriber = errors.New("subscriber is not configured anymore")
+
+// WebhookSubscriber is a downstream consumer of post events
+type WebhookSubscriber struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret signs deliveries the same way as C-3PO requests, see pkg/signature
+	Secret string `json:"secret,omitempty"`
+	// Headers are sent with every delivery, e.g. an Authorization header
+	Headers map[string]string `json:"headers,omitempty"`
+	// Events restricts the events sent to the subscriber, all of them when empty
+	Events []string `json:"events,omitempty"`
+	// MaxAttempts and RetryInterval (in seconds) override `WEBHOOK_MAX_ATTEMPTS` and `WEBHOOK_RETRY_INTERVAL` when set
+	MaxAttempts   int `json:"max_attempts,omitempty"`
+	RetryInterval int `json:"retry_interval,omitempty"`
+}
+
+// c3poSubscriber describes C-3PO as a subscriber of every event, retried according to the `DISPATCH_MAX_ATTEMPTS`
+// and `DISPATCH_RETRY_INTERVAL` settings. Its deliveries are sent by the Dispatcher rather than as webhooks.
+func c3poSubscriber() WebhookSubscriber {
+	return WebhookSubscriber{
+		Name:          c3poSubscriberName,
+		URL:           config.C3POURI,
+		MaxAttempts:   config.DispatchMaxAttempts,
+		RetryInterval: config.DispatchRetryInterval,
+	}
+}
+
+// maxAttempts is the number of failed attempts after which a delivery to the subscriber is dead-lettered
+func (s WebhookSubscriber) maxAttempts() int {
+	if s.MaxAttempts > 0 {
+		return s.MaxAttempts
+	}
+	return config.WebhookMaxAttempts
+}
+
+// retryInterval is how long to wait before retrying a delivery to the subscriber for the first time
+func (s WebhookSubscriber) retryInterval() time.Duration {
+	if s.RetryInterval > 0 {
+		return time.Duration(s.RetryInterval) * time.Second
+	}
+	return time.Duration(config.WebhookRetryInterval) * time.Second
+}
+
+// wants reports whether the subscriber is interested in an event
+func (s WebhookSubscriber) wants(event string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, wanted := range s.Events {
+		if wanted == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the body POSTed to webhook subscribers
+type WebhookEvent struct {
+	Event        string      `json:"event"`
+	FacebookID   string      `json:"facebook_id"`
+	FacebookPost fb.Result   `json:"facebook_post,omitempty"`
+	Comments     []fb.Result `json:"comments,omitempty"`
+	Links        []MusicLink `json:"links,omitempty"`
+	DeletedTime  *time.Time  `json:"deleted_time,omitempty"`
+}
+
+// webhookSubscribers is loaded from the `WEBHOOK_SUBSCRIBERS_FILE` env variable on startup
+var webhookSubscribers []WebhookSubscriber
+
+// loadWebhookSubscribers reads the JSON list of webhook subscribers at path. An empty path means no subscribers.
+func loadWebhookSubscribers(path string) ([]WebhookSubscriber, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var subscribers []WebhookSubscriber
+	if err := json.Unmarshal(content, &subscribers); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{c3poSubscriberName: true}
+	for _, subscriber := range subscribers {
+		if subscriber.Name == "" || subscriber.URL == "" {
+			return nil, errors.New("webhook subscribers need a name and a URL")
+		}
+		if names[subscriber.Name] {
+			return nil, fmt.Errorf("duplicate or reserved webhook subscriber name %q", subscriber.Name)
+		}
+		names[subscriber.Name] = true
+		if subscriber.MaxAttempts < 0 || subscriber.RetryInterval < 0 {
+			return nil, fmt.Errorf("negative retry settings for webhook subscriber %q", subscriber.Name)
+		}
+		for _, event := range subscriber.Events {
+			if event != webhookEventNew && event != webhookEventUpdated && event != webhookEventDeleted {
+				return nil, fmt.Errorf("unknown event %q for webhook subscriber %q", event, subscriber.Name)
+			}
+		}
+	}
+	return subscribers, nil
+}
+
+// findWebhookSubscriber returns the configured subscriber with the given name
+func findWebhookSubscriber(name string) (WebhookSubscriber, bool) {
+	for _, subscriber := range webhookSubscribers {
+		if subscriber.Name == name {
+			return subscriber, true
+		}
+	}
+	return WebhookSubscriber{}, false
+}
+
+// queueWebhookEvent queues an event of a post for C-3PO and every webhook subscriber interested in it. A pending
+// `new` event isn't downgraded to `updated`, since the subscriber hasn't seen the post yet.
+func queueWebhookEvent(ctx context.Context, store PostStore, facebookID string, event string, logger *zap.Logger) {
+	deliveries, err := store.ListWebhookDeliveries(ctx, facebookID)
+	if err != nil {
+		logger.Warn("Failed to read webhook deliveries", zap.String("FacebookID", facebookID), zap.Error(err))
+		return
+	}
+	previousDeliveries := map[string]WebhookDelivery{}
+	for _, delivery := range deliveries {
+		previousDeliveries[delivery.Subscriber] = delivery
+	}
+
+	for _, subscriber := range append([]WebhookSubscriber{c3poSubscriber()}, webhookSubscribers...) {
+		previous := previousDeliveries[subscriber.Name]
+		subscriberEvent := event
+		if event == webhookEventUpdated && previous.Status == webhookDeliveryPending && previous.Event == webhookEventNew {
+			subscriberEvent = webhookEventNew
+		}
+		if !subscriber.wants(subscriberEvent) {
+			continue
+		}
+		delivery := WebhookDelivery{
+			FacebookID:  facebookID,
+			Subscriber:  subscriber.Name,
+			Event:       subscriberEvent,
+			Status:      webhookDeliveryPending,
+			DeliveredAt: previous.DeliveredAt,
+			UpdatedAt:   time.Now().UTC(),
+		}
+		if err := store.UpdateOrInsertWebhookDelivery(ctx, delivery); err != nil {
+			logger.Warn("Failed to queue webhook delivery", zap.String("FacebookID", facebookID),
+				zap.String("subscriber", subscriber.Name), zap.Error(err))
+		}
+	}
+}
+
+// newWebhookEvent builds the event body of a delivery from the stored post
+func newWebhookEvent(ctx context.Context, store PostStore, delivery WebhookDelivery) (WebhookEvent, error) {
+	webhookEvent := WebhookEvent{Event: delivery.Event, FacebookID: delivery.FacebookID}
+	postData, err := store.GetPost(ctx, delivery.FacebookID)
+	if err != nil {
+		return webhookEvent, err
+	}
+	if delivery.Event == webhookEventDeleted {
+		webhookEvent.DeletedTime = postData.DeletedTime
+		return webhookEvent, nil
+	}
+
+	comments, err := store.ListComments(ctx, delivery.FacebookID)
+	if err != nil {
+		return webhookEvent, err
+	}
+	webhookEvent.FacebookPost = postData.FacebookPost
+	webhookEvent.Links = postData.Links
+	webhookEvent.Comments = make([]fb.Result, 0, len(comments))
+	for _, comment := range comments {
+		webhookEvent.Comments = append(webhookEvent.Comments, comment.FacebookComment)
+	}
+	return webhookEvent, nil
+}
+
+// sendWebhook POSTs an event to a subscriber, failing on any non-2xx response or once ctx is done
+func sendWebhook(ctx context.Context, subscriber WebhookSubscriber, webhookEvent WebhookEvent) error {
+	requestBody, err := json.Marshal(webhookEvent)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", subscriber.URL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	for name, value := range subscriber.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-R2D2-Event", webhookEvent.Event)
+	if subscriber.Secret != "" {
+		signature.SignRequest(req, []byte(subscriber.Secret), requestBody, time.Now())
+	}
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("subscriber responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// deliverWebhook sends a pending delivery to its webhook subscriber and stores the outcome, retried with the backoff
+// of the subscriber
+func deliverWebhook(ctx context.Context, store PostStore, delivery WebhookDelivery, logger *zap.Logger) error {
+	subscriber, ok := findWebhookSubscriber(delivery.Subscriber)
+	var err error
+	if !ok {
+		// Deliveries to subscribers removed from the config fail for good on their first attempt
+		subscriber = WebhookSubscriber{Name: delivery.Subscriber, MaxAttempts: 1}
+		err = errUnknownWebhookSubscriber
+	} else {
+		var webhookEvent WebhookEvent
+		webhookEvent, err = newWebhookEvent(ctx, store, delivery)
+		if err == nil {
+			err = sendWebhook(ctx, subscriber, webhookEvent)
+		}
+	}
+	return finishDelivery(ctx, store, subscriber, delivery, err, logger)
+}
+
+// DeliverWebhooks sends every due pending delivery to its webhook subscriber, returning early when ctx is cancelled.
+// Deliveries to C-3PO are left to the Dispatcher.
+func DeliverWebhooks(ctx context.Context, store PostStore, logger *zap.Logger) error {
+	// Collect first, since delivering changes the status index
+	now := time.Now()
+	var dueDeliveries []WebhookDelivery
+	err := store.QueryWebhookDeliveries(ctx, webhookDeliveryPending, func(delivery WebhookDelivery) bool {
+		if delivery.Subscriber != c3poSubscriberName && isDeliveryDue(delivery, now) {
+			dueDeliveries = append(dueDeliveries, delivery)
+		}
+		return true
+	})
+	if err != nil {
+		logger.Warn("Failed querying pending webhook deliveries", zap.Error(err))
+		return err
+	}
+
+	failedCount := 0
+	for _, delivery := range dueDeliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := deliverWebhook(ctx, store, delivery, logger); err != nil {
+			logger.Warn("Webhook delivery failed", zap.String("FacebookID", delivery.FacebookID),
+				zap.String("subscriber", delivery.Subscriber), zap.Error(err))
+			failedCount++
+		}
+	}
+	logger.Info("Delivered webhooks", zap.Int("attempted", len(dueDeliveries)), zap.Int("failed", failedCount))
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	fb "github.com/huandu/facebook/v2"
+	"github.com/lttkgp/R2-D2/pkg/signature"
+	"go.uber.org/zap"
+)
+
+func TestLoadWebhookSubscribers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{"valid", `[{"name": "search", "url": "http://search/hook", "events": ["new", "deleted"]}]`, true},
+		{"missing url", `[{"name": "search"}]`, false},
+		{"duplicate name", `[{"name": "search", "url": "http://a"}, {"name": "search", "url": "http://b"}]`, false},
+		{"reserved name", `[{"name": "c3po", "url": "http://c3po"}]`, false},
+		{"unknown event", `[{"name": "search", "url": "http://search/hook", "events": ["liked"]}]`, false},
+		{"retry settings", `[{"name": "search", "url": "http://search/hook", "max_attempts": 10, "retry_interval": 5}]`, true},
+		{"negative retry settings", `[{"name": "search", "url": "http://search/hook", "max_attempts": -1}]`, false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "webhooks.json")
+			if err := ioutil.WriteFile(path, []byte(testCase.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadWebhookSubscribers(path)
+			if (err == nil) != testCase.valid {
+				t.Errorf("Expected valid=%v, got %v", testCase.valid, err)
+			}
+		})
+	}
+
+	if subscribers, err := loadWebhookSubscribers(""); err != nil || len(subscribers) != 0 {
+		t.Errorf("Expected no subscribers without a file, got %v, %v", subscribers, err)
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]WebhookEvent{}
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/discord" {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path == "/search" {
+			verifyErr = signature.VerifyRequest(r, []byte("shared"))
+		}
+		var webhookEvent WebhookEvent
+		_ = json.NewDecoder(r.Body).Decode(&webhookEvent)
+		received[r.URL.Path] = append(received[r.URL.Path], webhookEvent)
+	}))
+	t.Cleanup(server.Close)
+	webhookSubscribers = []WebhookSubscriber{
+		{Name: "search", URL: server.URL + "/search", Secret: "shared"},
+		{Name: "deletions", URL: server.URL + "/deletions", Events: []string{webhookEventDeleted}},
+		{Name: "discord", URL: server.URL + "/discord", Events: []string{webhookEventNew}},
+	}
+	t.Cleanup(func() { webhookSubscribers = nil })
+
+	store := NewMemoryPostStore()
+	postData := PostData{
+		CreatedTime:  time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		FacebookID:   "1_1",
+		FacebookPost: fb.Result{"id": "1_1"},
+	}
+	_ = store.UpdateOrInsertPost(context.Background(), postData)
+	queueWebhookEvent(context.Background(), store, "1_1", webhookEventNew, zap.NewNop())
+	// The post is edited before the first delivery, subscribers still get to see it as new
+	queueWebhookEvent(context.Background(), store, "1_1", webhookEventUpdated, zap.NewNop())
+
+	if err := DeliverWebhooks(context.Background(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received["/search"]) != 1 || received["/search"][0].Event != webhookEventNew || received["/search"][0].FacebookPost["id"] != "1_1" {
+		t.Errorf("Expected search to receive the new post once, got %+v", received["/search"])
+	}
+	if verifyErr != nil {
+		t.Errorf("Expected the search delivery to be signed, got %v", verifyErr)
+	}
+	if len(received["/deletions"]) != 0 {
+		t.Errorf("Expected deletions not to receive new posts, got %+v", received["/deletions"])
+	}
+
+	deliveries, _ := store.ListWebhookDeliveries(context.Background(), "1_1")
+	statuses := map[string]WebhookDelivery{}
+	for _, delivery := range deliveries {
+		statuses[delivery.Subscriber] = delivery
+	}
+	if statuses["search"].Status != webhookDeliveryDelivered || statuses["search"].DeliveredAt == nil {
+		t.Errorf("Expected the search delivery to succeed, got %+v", statuses["search"])
+	}
+	discord := statuses["discord"]
+	if discord.Status != webhookDeliveryPending || discord.Attempts != 1 || discord.NextAttemptTime == nil || discord.LastError == "" {
+		t.Errorf("Expected the discord delivery to be retried later, got %+v", discord)
+	}
+
+	// Deletions only reach the subscribers asking for them
+	deletedTime := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+	postData.DeletedTime = &deletedTime
+	_ = store.MarkPostAsDeleted(context.Background(), postData)
+	queueWebhookEvent(context.Background(), store, "1_1", webhookEventDeleted, zap.NewNop())
+	if err := DeliverWebhooks(context.Background(), store, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received["/deletions"]) != 1 || received["/deletions"][0].DeletedTime == nil {
+		t.Errorf("Expected deletions to receive the deleted post, got %+v", received["/deletions"])
+	}
+	if len(received["/search"]) != 2 || received["/search"][1].Event != webhookEventDeleted {
+		t.Errorf("Expected search to receive the deletion, got %+v", received["/search"])
+	}
+}
@@ -0,0 +1,272 @@
+// Package fakec3po is an in-process stand-in for C-3PO, so that R2-D2's dispatch of posts can be tested end to end.
+//
+// A Server records every post and deletion it receives, and accepts them unless told otherwise with Respond,
+// FailNext or DisableBatch. It can also require requests to be signed like R2-D2 signs them, see pkg/signature.
+// Point R2-D2 at the server by setting C3PO_URI to URL.
+package fakec3po
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/lttkgp/R2-D2/pkg/signature"
+)
+
+// Link is a music link sent along with a post
+type Link struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	ID       string `json:"id,omitempty"`
+	Source   string `json:"source"`
+}
+
+// Post is a post received by C-3PO, on its own or as part of a batch
+type Post struct {
+	// FacebookID is only sent for posts of a batch, the ID of a single post is in FacebookPost
+	FacebookID   string                   `json:"facebook_id,omitempty"`
+	FacebookPost map[string]interface{}   `json:"facebook_post"`
+	Comments     []map[string]interface{} `json:"comments"`
+	Links        []Link                   `json:"links"`
+}
+
+// ID returns the Facebook ID of the post
+func (p Post) ID() string {
+	if p.FacebookID != "" {
+		return p.FacebookID
+	}
+	id, _ := p.FacebookPost["id"].(string)
+	return id
+}
+
+// Deletion is a deleted post reported to C-3PO
+type Deletion struct {
+	FacebookID  string    `json:"facebook_id"`
+	DeletedTime time.Time `json:"deleted_time"`
+}
+
+// Result is C-3PO's verdict on a post
+type Result struct {
+	Success bool
+	// Error is returned for failed posts of a batch
+	Error string
+}
+
+// Request is a request received by the server
+type Request struct {
+	Path   string
+	Header http.Header
+}
+
+type itemResult struct {
+	FacebookID string `json:"facebook_id"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
+
+type response struct {
+	Success bool         `json:"success"`
+	Results []itemResult `json:"results,omitempty"`
+}
+
+// Server is a fake C-3PO recording the posts it receives
+type Server struct {
+	// URL is the base URL of the server, to be used as C3PO_URI
+	URL string
+
+	server        *httptest.Server
+	mu            sync.Mutex
+	respond       func(post Post) Result
+	failures      []int
+	batchDisabled bool
+	secret        []byte
+	posts         []Post
+	deletions     []Deletion
+	requests      []Request
+}
+
+// New starts a Server accepting every post. Close it once done.
+func New() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/data/post", s.servePost)
+	mux.HandleFunc("/v1/data/post/batch", s.serveBatch)
+	mux.HandleFunc("/v1/data/post/delete", s.serveDelete)
+	s.server = httptest.NewServer(s.record(mux))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Respond decides the result of every post received from now on. Posts are accepted by default.
+func (s *Server) Respond(fn func(post Post) Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.respond = fn
+}
+
+// FailNext answers the next n requests with the HTTP status, without looking at them
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// DisableBatch makes the batch endpoint answer 404, like C-3PO versions without it
+func (s *Server) DisableBatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batchDisabled = true
+}
+
+// RequireSignature rejects requests that aren't signed with the secret, with a 401
+func (s *Server) RequireSignature(secret []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secret = secret
+}
+
+// Posts returns the posts received so far, oldest first. Posts sent again are listed again.
+func (s *Server) Posts() []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	posts := make([]Post, len(s.posts))
+	copy(posts, s.posts)
+	return posts
+}
+
+// Deletions returns the deletions received so far, oldest first
+func (s *Server) Deletions() []Deletion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deletions := make([]Deletion, len(s.deletions))
+	copy(deletions, s.deletions)
+	return deletions
+}
+
+// Requests returns the requests received so far, oldest first, rejected ones included
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// record logs every request, and answers it with a queued failure or a signature error before it reaches next
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Path: r.URL.Path, Header: r.Header.Clone()})
+		var status int
+		if len(s.failures) > 0 {
+			status, s.failures = s.failures[0], s.failures[1:]
+		}
+		secret := s.secret
+		s.mu.Unlock()
+
+		if status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if secret != nil {
+			if err := signature.VerifyRequest(r, secret); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) servePost(w http.ResponseWriter, r *http.Request) {
+	var post Post
+	if !decodeBody(w, r, &post) {
+		return
+	}
+	writeJSON(w, response{Success: s.receive(post).Success})
+}
+
+func (s *Server) serveBatch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	batchDisabled := s.batchDisabled
+	s.mu.Unlock()
+	if batchDisabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	var batch struct {
+		Posts []Post `json:"posts"`
+	}
+	if !decodeBody(w, r, &batch) {
+		return
+	}
+	resp := response{Success: true, Results: make([]itemResult, 0, len(batch.Posts))}
+	for _, post := range batch.Posts {
+		result := s.receive(post)
+		resp.Results = append(resp.Results, itemResult{FacebookID: post.ID(), Success: result.Success, Error: result.Error})
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) serveDelete(w http.ResponseWriter, r *http.Request) {
+	var deletion Deletion
+	if !decodeBody(w, r, &deletion) {
+		return
+	}
+	s.mu.Lock()
+	s.deletions = append(s.deletions, deletion)
+	s.mu.Unlock()
+	writeJSON(w, response{Success: true})
+}
+
+// receive records a post and decides its result
+func (s *Server) receive(post Post) Result {
+	s.mu.Lock()
+	s.posts = append(s.posts, post)
+	respond := s.respond
+	s.mu.Unlock()
+
+	if respond == nil {
+		return Result{Success: true}
+	}
+	return respond(post)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, out interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, out)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,88 @@
+package fakec3po
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lttkgp/R2-D2/pkg/signature"
+)
+
+func post(t *testing.T, url string, body string, secret []byte) (int, response) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != nil {
+		signature.SignRequest(req, secret, []byte(body), time.Now())
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded response
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestServer(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.Respond(func(post Post) Result {
+		return Result{Success: post.ID() != "1_2", Error: "no song found"}
+	})
+
+	if status, resp := post(t, server.URL+"/v1/data/post", `{"facebook_post": {"id": "1_1"}, "comments": [], "links": []}`, nil); status != http.StatusOK || !resp.Success {
+		t.Errorf("Expected the post to be accepted, got %d %+v", status, resp)
+	}
+	status, resp := post(t, server.URL+"/v1/data/post/batch", `{"posts": [
+		{"facebook_id": "1_2", "facebook_post": {"id": "1_2"}},
+		{"facebook_id": "1_3", "facebook_post": {"id": "1_3"}}
+	]}`, nil)
+	if status != http.StatusOK || len(resp.Results) != 2 || resp.Results[0].Success || resp.Results[0].Error != "no song found" || !resp.Results[1].Success {
+		t.Errorf("Expected a result per post of the batch, got %d %+v", status, resp)
+	}
+	if status, _ := post(t, server.URL+"/v1/data/post/delete", `{"facebook_id": "1_1", "deleted_time": "2020-10-01T00:00:00Z"}`, nil); status != http.StatusOK {
+		t.Errorf("Expected the deletion to be accepted, got %d", status)
+	}
+
+	posts := server.Posts()
+	if len(posts) != 3 || posts[0].ID() != "1_1" || posts[2].ID() != "1_3" {
+		t.Errorf("Expected every post to be recorded, got %+v", posts)
+	}
+	if deletions := server.Deletions(); len(deletions) != 1 || deletions[0].FacebookID != "1_1" {
+		t.Errorf("Expected the deletion to be recorded, got %+v", deletions)
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.FailNext(1, http.StatusServiceUnavailable)
+	server.DisableBatch()
+	server.RequireSignature([]byte("secret"))
+	body := `{"facebook_post": {"id": "1_1"}}`
+
+	if status, _ := post(t, server.URL+"/v1/data/post", body, []byte("secret")); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected the queued failure, got %d", status)
+	}
+	if status, _ := post(t, server.URL+"/v1/data/post", body, []byte("other")); status != http.StatusUnauthorized {
+		t.Errorf("Expected a wrongly signed request to be rejected, got %d", status)
+	}
+	if status, _ := post(t, server.URL+"/v1/data/post/batch", `{"posts": []}`, []byte("secret")); status != http.StatusNotFound {
+		t.Errorf("Expected the batch endpoint to be missing, got %d", status)
+	}
+	if status, _ := post(t, server.URL+"/v1/data/post", body, []byte("secret")); status != http.StatusOK {
+		t.Errorf("Expected a signed request to be accepted, got %d", status)
+	}
+	if len(server.Posts()) != 1 || len(server.Requests()) != 4 {
+		t.Errorf("Expected only the accepted post to be recorded, got %+v", server.Posts())
+	}
+}
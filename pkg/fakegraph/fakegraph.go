@@ -0,0 +1,355 @@
+// Package fakegraph is an in-process stand-in for the Facebook Graph API, so that code paging through a group feed
+// can be tested offline.
+//
+// A Server serves the feed pages it's given, in order, linked by `after` cursors. Every post of the feed can also be
+// fetched by its ID, along with the comments added with AddComments and not removed with DeleteComment, and so can the group owning the feed, whose ID
+// prefixes the post IDs. Errors, such as rate limits, can be queued for
+// the next requests of a path. Point a session at the server with Session, or by setting its BaseURL to URL + "/".
+package fakegraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// versionPattern matches the Graph API version prefixing request paths, e.g. v8.0
+var versionPattern = regexp.MustCompile(`^v[0-9]+\.[0-9]+$`)
+
+// Error is a Graph API error response
+type Error struct {
+	// Status is the HTTP status of the response, 400 if unset
+	Status  int
+	Message string
+	Type    string
+	Code    int
+	Subcode int
+}
+
+// RateLimitError is returned by the Graph API when the app made too many calls
+func RateLimitError() Error {
+	return Error{Status: http.StatusForbidden, Message: "(#4) Application request limit reached", Type: "OAuthException", Code: 4}
+}
+
+// NotFoundError is returned by the Graph API for a deleted or unknown object
+func NotFoundError() Error {
+	return Error{Status: http.StatusBadRequest, Message: "Unsupported get request.", Type: "GraphMethodException", Code: 100, Subcode: 33}
+}
+
+// Request is a request received by the server
+type Request struct {
+	// Path is the requested Graph API path, without the version, e.g. 1488511748129645/feed
+	Path  string
+	Query url.Values
+}
+
+type queuedError struct {
+	pathSuffix string
+	err        Error
+}
+
+// Server is a fake Graph API serving recorded feed pages
+type Server struct {
+	// URL is the base URL of the server, without a trailing slash
+	URL string
+
+	server    *httptest.Server
+	mu        sync.Mutex
+	feedPages [][]fb.Result
+	posts     map[string]fb.Result
+	groups    map[string]bool
+	comments  map[string][]fb.Result
+	// commentPageSize is the number of comments per page of a comment thread, all of them when zero
+	commentPageSize int
+	deleted         map[string]bool
+	errors          []queuedError
+	requests        []Request
+}
+
+// New starts a Server with an empty feed. Close it once done.
+func New() *Server {
+	s := &Server{
+		posts:    map[string]fb.Result{},
+		groups:   map[string]bool{},
+		comments: map[string][]fb.Result{},
+		deleted:  map[string]bool{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Session returns a Graph API session pointed at the server, configured like the one R2-D2 uses
+func (s *Server) Session() *fb.Session {
+	fbSession := fb.New("app", "secret").Session("token")
+	fbSession.BaseURL = s.URL + "/"
+	fbSession.RFC3339Timestamps = true
+	fbSession.Version = "v8.0"
+	return fbSession
+}
+
+// AddFeedPage appends a page of posts to the feed. Every post must have an `id`, made of the group ID and the post ID
+// joined by an underscore.
+func (s *Server) AddFeedPage(posts ...fb.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.feedPages = append(s.feedPages, posts)
+	for _, post := range posts {
+		if id, ok := post["id"].(string); ok {
+			s.posts[id] = post
+			if i := strings.Index(id, "_"); i > 0 {
+				s.groups[id[:i]] = true
+			}
+		}
+	}
+}
+
+// LoadFeedPages appends recorded feed pages to the feed. Every file holds a Graph API response to a feed request,
+// whose `paging` is ignored in favor of the server's own cursors.
+func (s *Server) LoadFeedPages(paths ...string) error {
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var page struct {
+			Data []fb.Result `json:"data"`
+		}
+		if err := json.Unmarshal(content, &page); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		s.AddFeedPage(page.Data...)
+	}
+	return nil
+}
+
+// AddComments appends comments to the comment thread of a post
+func (s *Server) AddComments(postID string, comments ...fb.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.comments[postID] = append(s.comments[postID], comments...)
+}
+
+// DeleteComment removes a comment from the comment thread of a post
+func (s *Server) DeleteComment(postID string, commentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []fb.Result
+	for _, comment := range s.comments[postID] {
+		if comment["id"] != commentID {
+			kept = append(kept, comment)
+		}
+	}
+	s.comments[postID] = kept
+}
+
+// SetCommentPageSize splits comment threads into pages of n comments, linked with `after` cursors
+func (s *Server) SetCommentPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commentPageSize = n
+}
+
+// DeletePost makes a post of the feed answer with NotFoundError when fetched by its ID. It's still listed in the feed.
+func (s *Server) DeletePost(postID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleted[postID] = true
+}
+
+// FailNext answers the next n requests whose path ends with pathSuffix with err. An empty suffix matches any path.
+// Queued errors are returned in the order they were queued.
+func (s *Server) FailNext(pathSuffix string, n int, err Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.errors = append(s.errors, queuedError{pathSuffix: pathSuffix, err: err})
+	}
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// FeedRequests returns the `after` cursor of every feed request received so far, empty for the first page
+func (s *Server) FeedRequests() []string {
+	var cursors []string
+	for _, request := range s.Requests() {
+		if strings.HasSuffix(request.Path, "/feed") {
+			cursors = append(cursors, request.Query.Get("after"))
+		}
+	}
+	return cursors
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) > 0 && versionPattern.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+	path := strings.Join(segments, "/")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: path, Query: r.Form})
+	for i, queued := range s.errors {
+		if strings.HasSuffix(path, queued.pathSuffix) {
+			s.errors = append(s.errors[:i:i], s.errors[i+1:]...)
+			s.mu.Unlock()
+			writeError(w, queued.err)
+			return
+		}
+	}
+	s.mu.Unlock()
+
+	switch {
+	case len(segments) == 2 && segments[1] == "feed":
+		s.serveFeed(w, r)
+	case len(segments) == 2 && segments[1] == "comments":
+		s.serveComments(w, r, segments[0])
+	case len(segments) == 1 && segments[0] != "":
+		s.servePost(w, segments[0])
+	default:
+		writeError(w, NotFoundError())
+	}
+}
+
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	pageIndex := 0
+	if after := r.Form.Get("after"); after != "" {
+		var err error
+		if pageIndex, err = strconv.Atoi(after); err != nil {
+			writeError(w, Error{Message: "Invalid cursor", Type: "OAuthException", Code: 100})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	var posts []fb.Result
+	if pageIndex < len(s.feedPages) {
+		posts = s.feedPages[pageIndex]
+	}
+	hasNext := pageIndex+1 < len(s.feedPages)
+	s.mu.Unlock()
+
+	if posts == nil {
+		posts = []fb.Result{}
+	}
+	response := fb.Result{"data": posts}
+	if hasNext {
+		response["paging"] = nextPaging(r, pageIndex+1)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) serveComments(w http.ResponseWriter, r *http.Request, postID string) {
+	offset := 0
+	if after := r.Form.Get("after"); after != "" {
+		var err error
+		if offset, err = strconv.Atoi(after); err != nil {
+			writeError(w, Error{Message: "Invalid cursor", Type: "OAuthException", Code: 100})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	comments := s.comments[postID]
+	end := len(comments)
+	if s.commentPageSize > 0 && offset+s.commentPageSize < end {
+		end = offset + s.commentPageSize
+	}
+	var page []fb.Result
+	if offset < end {
+		page = comments[offset:end]
+	}
+	hasNext := end < len(comments)
+	s.mu.Unlock()
+
+	if page == nil {
+		page = []fb.Result{}
+	}
+	response := fb.Result{"data": page}
+	if hasNext {
+		response["paging"] = nextPaging(r, end)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// nextPaging links a response to the page of the same request starting at the after cursor
+func nextPaging(r *http.Request, after int) fb.Result {
+	nextQuery := url.Values{}
+	for key, values := range r.Form {
+		nextQuery[key] = values
+	}
+	nextQuery.Set("after", strconv.Itoa(after))
+	return fb.Result{
+		"cursors": fb.Result{"after": strconv.Itoa(after)},
+		"next":    fmt.Sprintf("http://%s%s?%s", r.Host, r.URL.Path, nextQuery.Encode()),
+	}
+}
+
+func (s *Server) servePost(w http.ResponseWriter, postID string) {
+	s.mu.Lock()
+	post, ok := s.posts[postID]
+	deleted := s.deleted[postID]
+	group := s.groups[postID]
+	s.mu.Unlock()
+
+	if group {
+		writeJSON(w, http.StatusOK, fb.Result{"id": postID})
+		return
+	}
+	if !ok || deleted {
+		writeError(w, NotFoundError())
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+func writeError(w http.ResponseWriter, err Error) {
+	status := err.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	body := fb.Result{
+		"message": err.Message,
+		"type":    err.Type,
+		"code":    err.Code,
+	}
+	if err.Subcode != 0 {
+		body["error_subcode"] = err.Subcode
+	}
+	writeJSON(w, status, fb.Result{"error": body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,111 @@
+package fakegraph
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+func TestFeedPaging(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.AddFeedPage(fb.Result{"id": "1_1"}, fb.Result{"id": "1_2"})
+	server.AddFeedPage(fb.Result{"id": "1_3"})
+	fbSession := server.Session()
+
+	feedResp, err := fbSession.Get("1/feed", fb.Params{"limit": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paging, err := feedResp.Paging(fbSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for {
+		for _, post := range paging.Data() {
+			ids = append(ids, post["id"].(string))
+		}
+		noMore, err := paging.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if noMore {
+			break
+		}
+	}
+	if strings.Join(ids, ",") != "1_1,1_2,1_3" {
+		t.Errorf("Expected every post of the feed, got %v", ids)
+	}
+	if cursors := server.FeedRequests(); strings.Join(cursors, ",") != ",1" {
+		t.Errorf("Expected both pages to be requested once, got %v", cursors)
+	}
+	if query := server.Requests()[1].Query; query.Get("limit") != "2" {
+		t.Errorf("Expected the next page to keep the query of the first, got %v", query)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.AddFeedPage(fb.Result{"id": "1_1"})
+	server.FailNext("/feed", 1, RateLimitError())
+	fbSession := server.Session()
+
+	_, err := fbSession.Get("1/feed", nil)
+	var fbError *fb.Error
+	if !errors.As(err, &fbError) || fbError.Code != 4 {
+		t.Fatalf("Expected a rate limit error, got %v", err)
+	}
+	if _, err := fbSession.Get("1_1", nil); err != nil {
+		t.Errorf("Expected errors queued for the feed to leave posts alone, got %v", err)
+	}
+	if _, err := fbSession.Get("1/feed", nil); err != nil {
+		t.Errorf("Expected the feed to be served once the error is consumed, got %v", err)
+	}
+}
+
+func TestDeletePost(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.AddFeedPage(fb.Result{"id": "1_1", "message": "song"})
+	server.AddComments("1_1", fb.Result{"id": "1_1_c1"})
+	fbSession := server.Session()
+
+	post, err := fbSession.Get("1_1", nil)
+	if err != nil || post["message"] != "song" {
+		t.Fatalf("Expected the post, got %v, %v", post, err)
+	}
+	comments, err := fbSession.Get("1_1/comments", nil)
+	if err != nil || len(comments["data"].([]interface{})) != 1 {
+		t.Errorf("Expected the comment, got %v, %v", comments, err)
+	}
+
+	server.DeletePost("1_1")
+	_, err = fbSession.Get("1_1", nil)
+	var fbError *fb.Error
+	if !errors.As(err, &fbError) || fbError.Code != 100 || fbError.ErrorSubcode != 33 {
+		t.Errorf("Expected a deleted post to be reported missing, got %v", err)
+	}
+	if group, err := fbSession.Get("1", nil); err != nil || group["id"] != "1" {
+		t.Errorf("Expected the group to be served, got %v, %v", group, err)
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.AddComments("1_1", fb.Result{"id": "1_1_c1"}, fb.Result{"id": "1_1_c2"})
+	server.DeleteComment("1_1", "1_1_c1")
+
+	comments, err := server.Session().Get("1_1/comments", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := comments["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["id"] != "1_1_c2" {
+		t.Errorf("Expected only the remaining comment, got %v", data)
+	}
+}
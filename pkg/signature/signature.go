@@ -0,0 +1,93 @@
+// Package signature signs the requests R2-D2 sends to C-3PO, and verifies them on the receiving end.
+//
+// A request is signed with an HMAC-SHA256 of its timestamp and body, keyed by a secret shared by both services.
+// The timestamp is sent in the TimestampHeader, and the signature in the SignatureHeader as `sha256=<hex digest>`.
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time at which the request was signed
+	TimestampHeader = "X-R2D2-Timestamp"
+	// SignatureHeader carries the HMAC of the timestamp and body
+	SignatureHeader = "X-R2D2-Signature"
+	// DefaultMaxSkew is how old (or how far in the future) a signed request may be before it's rejected as a replay
+	DefaultMaxSkew = 5 * time.Minute
+)
+
+var (
+	// ErrMissingSignature is returned when a request doesn't carry both signature headers
+	ErrMissingSignature = errors.New("missing signature headers")
+	// ErrInvalidTimestamp is returned when the timestamp header isn't a Unix time
+	ErrInvalidTimestamp = errors.New("invalid signature timestamp")
+	// ErrExpiredTimestamp is returned when the request was signed too long ago, or too far in the future
+	ErrExpiredTimestamp = errors.New("signature timestamp outside of the allowed window")
+	// ErrInvalidSignature is returned when the signature doesn't match the timestamp and body
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Sign returns the value of the SignatureHeader for a body signed at the given Unix timestamp
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the signature headers of req for the given body, signed at now
+func SignRequest(req *http.Request, secret []byte, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+}
+
+// Verify checks a signature and timestamp header value against the body, rejecting timestamps more than
+// maxSkew away from now
+func Verify(secret []byte, timestampHeader, signatureHeader string, body []byte, now time.Time, maxSkew time.Duration) error {
+	if timestampHeader == "" || signatureHeader == "" {
+		return ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := now.Sub(time.Unix(timestamp, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return ErrExpiredTimestamp
+	}
+	if !strings.HasPrefix(signatureHeader, "sha256=") {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signatureHeader)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyRequest checks the signature headers of an incoming request with DefaultMaxSkew.
+// The request body is read and replaced, so that handlers can still decode it afterwards.
+func VerifyRequest(r *http.Request, secret []byte) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		_ = r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return Verify(secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, time.Now(), DefaultMaxSkew)
+}
@@ -0,0 +1,79 @@
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("shared")
+	body := []byte(`{"facebook_id":"1_1"}`)
+	now := time.Unix(1600000000, 0)
+	signature := Sign(secret, now.Unix(), body)
+
+	testCases := []struct {
+		name      string
+		secret    []byte
+		timestamp string
+		signature string
+		body      []byte
+		expected  error
+	}{
+		{"valid", secret, "1600000000", signature, body, nil},
+		{"missing headers", secret, "", "", body, ErrMissingSignature},
+		{"malformed timestamp", secret, "yesterday", signature, body, ErrInvalidTimestamp},
+		{"replayed", secret, "1599999000", Sign(secret, 1599999000, body), body, ErrExpiredTimestamp},
+		{"tampered body", secret, "1600000000", signature, []byte(`{"facebook_id":"1_2"}`), ErrInvalidSignature},
+		{"wrong secret", []byte("other"), "1600000000", signature, body, ErrInvalidSignature},
+		{"timestamp swapped", secret, "1600000001", signature, body, ErrInvalidSignature},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Verify(testCase.secret, testCase.timestamp, testCase.signature, testCase.body, now, DefaultMaxSkew)
+			if err != testCase.expected {
+				t.Errorf("Expected %v, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	secret := []byte("shared")
+	body := []byte(`{"facebook_id":"1_1"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := VerifyRequest(r, secret); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		received, _ := ioutil.ReadAll(r.Body)
+		if !bytes.Equal(received, body) {
+			t.Errorf("Expected the body to be readable after verification, got %q", received)
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewReader(body))
+	SignRequest(req, secret, body, time.Now())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a signed request to be accepted, got %d", resp.StatusCode)
+	}
+
+	req, _ = http.NewRequest("POST", server.URL, bytes.NewReader(body))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an unsigned request to be rejected, got %d", resp.StatusCode)
+	}
+}
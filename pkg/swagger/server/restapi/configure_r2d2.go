@@ -38,11 +38,74 @@ func configureAPI(api *operations.R2d2API) http.Handler {
 	api.JSONProducer = runtime.JSONProducer()
 	api.TxtProducer = runtime.TextProducer()
 
+	// Applies when the "X-Admin-Token" header is set
+	if api.AdminTokenAuth == nil {
+		api.AdminTokenAuth = func(token string) (interface{}, error) {
+			return nil, errors.NotImplemented("api key auth (AdminToken) X-Admin-Token from header param [X-Admin-Token] has not yet been implemented")
+		}
+	}
+
+	// Set your custom authorizer if needed. Default one is security.Authorized()
+	// Expected interface runtime.Authorizer
+	//
+	// Example:
+	// api.APIAuthorizer = security.Authorized()
+
 	if api.CheckHealthHandler == nil {
 		api.CheckHealthHandler = operations.CheckHealthHandlerFunc(func(params operations.CheckHealthParams) middleware.Responder {
 			return middleware.NotImplemented("operation operations.CheckHealth has not yet been implemented")
 		})
 	}
+	if api.GetBackfillHandler == nil {
+		api.GetBackfillHandler = operations.GetBackfillHandlerFunc(func(params operations.GetBackfillParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.GetBackfill has not yet been implemented")
+		})
+	}
+	if api.GetPostHandler == nil {
+		api.GetPostHandler = operations.GetPostHandlerFunc(func(params operations.GetPostParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.GetPost has not yet been implemented")
+		})
+	}
+	if api.ListEngagementSnapshotsHandler == nil {
+		api.ListEngagementSnapshotsHandler = operations.ListEngagementSnapshotsHandlerFunc(func(params operations.ListEngagementSnapshotsParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.ListEngagementSnapshots has not yet been implemented")
+		})
+	}
+	if api.ListPostsHandler == nil {
+		api.ListPostsHandler = operations.ListPostsHandlerFunc(func(params operations.ListPostsParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.ListPosts has not yet been implemented")
+		})
+	}
+	if api.ReceiveFacebookWebhookHandler == nil {
+		api.ReceiveFacebookWebhookHandler = operations.ReceiveFacebookWebhookHandlerFunc(func(params operations.ReceiveFacebookWebhookParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.ReceiveFacebookWebhook has not yet been implemented")
+		})
+	}
+	if api.RedispatchPostHandler == nil {
+		api.RedispatchPostHandler = operations.RedispatchPostHandlerFunc(func(params operations.RedispatchPostParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation operations.RedispatchPost has not yet been implemented")
+		})
+	}
+	if api.RedispatchPostsHandler == nil {
+		api.RedispatchPostsHandler = operations.RedispatchPostsHandlerFunc(func(params operations.RedispatchPostsParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation operations.RedispatchPosts has not yet been implemented")
+		})
+	}
+	if api.ReplayDeadLettersHandler == nil {
+		api.ReplayDeadLettersHandler = operations.ReplayDeadLettersHandlerFunc(func(params operations.ReplayDeadLettersParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation operations.ReplayDeadLetters has not yet been implemented")
+		})
+	}
+	if api.StartBackfillHandler == nil {
+		api.StartBackfillHandler = operations.StartBackfillHandlerFunc(func(params operations.StartBackfillParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation operations.StartBackfill has not yet been implemented")
+		})
+	}
+	if api.VerifyFacebookWebhookHandler == nil {
+		api.VerifyFacebookWebhookHandler = operations.VerifyFacebookWebhookHandlerFunc(func(params operations.VerifyFacebookWebhookParams) middleware.Responder {
+			return middleware.NotImplemented("operation operations.VerifyFacebookWebhook has not yet been implemented")
+		})
+	}
 
 	api.PreServerShutdown = func() {}
 
@@ -52,6 +52,751 @@ func init() {
           }
         }
       }
+    },
+    "/v1/admin/backfill": {
+      "get": {
+        "summary": "Get the progress of the historical backfill",
+        "operationId": "getBackfill",
+        "responses": {
+          "200": {
+            "description": "Progress of the last backfill",
+            "schema": {
+              "$ref": "#/definitions/BackfillStatus"
+            }
+          },
+          "404": {
+            "description": "No backfill has been started yet",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Walks the whole group feed back to ` + "`" + `since` + "`" + ` in the background. A previous backfill with the same ` + "`" + `since` + "`" + ` that didn't finish is resumed from its last page.",
+        "summary": "Start a historical backfill of the group feed",
+        "operationId": "startBackfill",
+        "parameters": [
+          {
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/BackfillRequest"
+            }
+          }
+        ],
+        "responses": {
+          "202": {
+            "description": "Backfill started",
+            "schema": {
+              "$ref": "#/definitions/BackfillStatus"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "A backfill is already running",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts": {
+      "get": {
+        "description": "Returns a page of the Facebook posts stored by R2-D2. Pass ` + "`" + `next_cursor` + "`" + ` from the response as ` + "`" + `cursor` + "`" + ` to fetch the next page.",
+        "summary": "List stored posts",
+        "operationId": "listPosts",
+        "parameters": [
+          {
+            "maximum": 100,
+            "minimum": 1,
+            "type": "integer",
+            "format": "int64",
+            "default": 20,
+            "description": "Maximum number of posts in the page",
+            "name": "limit",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Opaque cursor returned as ` + "`" + `next_cursor` + "`" + ` by the previous page",
+            "name": "cursor",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return posts created at or after this time",
+            "name": "created_after",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return posts created at or before this time",
+            "name": "created_before",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "description": "Only return posts that have (or are waiting to be) parsed by C-3PO",
+            "name": "is_parsed",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Only return posts whose delivery to C-3PO ran out of attempts",
+            "name": "dead_lettered",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of stored posts",
+            "schema": {
+              "$ref": "#/definitions/PostList"
+            }
+          },
+          "400": {
+            "description": "Invalid cursor",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/dead-letters/replay": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Queues the deliveries to C-3PO and the webhook subscribers that ran out of attempts with a fresh attempt count, or attempts them right away when ` + "`" + `immediate` + "`" + ` is set.",
+        "summary": "Send every dead-lettered delivery again",
+        "operationId": "replayDeadLetters",
+        "parameters": [
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Attempt the deliveries now instead of waiting for the next run",
+            "name": "immediate",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the replay for every dead-lettered delivery",
+            "schema": {
+              "$ref": "#/definitions/RedispatchReport"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/redispatch": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "summary": "Send all posts in a time range to C-3PO again",
+        "operationId": "redispatchPosts",
+        "parameters": [
+          {
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/RedispatchRequest"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the redispatch for every matching post",
+            "schema": {
+              "$ref": "#/definitions/RedispatchReport"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}": {
+      "get": {
+        "description": "Returns the post as stored by R2-D2, along with its parse status and every attempt at dispatching it to C-3PO.",
+        "summary": "Get a stored post",
+        "operationId": "getPost",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The stored post",
+            "schema": {
+              "$ref": "#/definitions/Post"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}/engagement": {
+      "get": {
+        "description": "Totals are sampled on every fetch while the post is younger than ` + "`" + `ENGAGEMENT_SNAPSHOT_MAX_AGE_DAYS` + "`" + `.",
+        "summary": "Get the reaction and comment totals of a post over time",
+        "operationId": "listEngagementSnapshots",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Engagement samples, oldest first",
+            "schema": {
+              "$ref": "#/definitions/EngagementSnapshotList"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}/redispatch": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Queues the post for the next dispatcher run, or dispatches it right away when ` + "`" + `immediate` + "`" + ` is set.",
+        "summary": "Send a post to C-3PO again",
+        "operationId": "redispatchPost",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Dispatch the post now instead of waiting for the dispatcher",
+            "name": "immediate",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the redispatch",
+            "schema": {
+              "$ref": "#/definitions/RedispatchResult"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/webhooks/facebook": {
+      "get": {
+        "description": "Echoes ` + "`" + `hub.challenge` + "`" + ` back when ` + "`" + `hub.verify_token` + "`" + ` matches ` + "`" + `FB_WEBHOOK_VERIFY_TOKEN` + "`" + `.",
+        "produces": [
+          "text/plain"
+        ],
+        "summary": "Answer the subscription verification request of Facebook Webhooks",
+        "operationId": "verifyFacebookWebhook",
+        "parameters": [
+          {
+            "type": "string",
+            "name": "hub.mode",
+            "in": "query",
+            "required": true
+          },
+          {
+            "type": "string",
+            "name": "hub.verify_token",
+            "in": "query",
+            "required": true
+          },
+          {
+            "type": "string",
+            "name": "hub.challenge",
+            "in": "query",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The challenge",
+            "schema": {
+              "type": "string"
+            }
+          },
+          "403": {
+            "description": "Wrong mode or verify token"
+          }
+        }
+      },
+      "post": {
+        "description": "Stores every post the notification mentions right away, or tombstones it when it was removed. The body is the raw notification, signed with the app secret.",
+        "summary": "Receive a Facebook Webhooks notification for the group feed",
+        "operationId": "receiveFacebookWebhook",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "HMAC-SHA256 of the body with the app secret, as ` + "`" + `sha256=\u003chex digest\u003e` + "`" + `",
+            "name": "X-Hub-Signature-256",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Notification processed"
+          },
+          "400": {
+            "description": "Malformed notification",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "Missing or invalid signature",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "BackfillRequest": {
+      "type": "object",
+      "required": [
+        "since"
+      ],
+      "properties": {
+        "since": {
+          "description": "Backfill posts created at or after this time",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "BackfillStatus": {
+      "type": "object",
+      "properties": {
+        "done": {
+          "description": "Whether the backfill reached ` + "`" + `since` + "`" + ` or the end of the feed",
+          "type": "boolean"
+        },
+        "error": {
+          "description": "Why the last run failed",
+          "type": "string"
+        },
+        "posts_fetched": {
+          "description": "Number of posts stored so far",
+          "type": "integer",
+          "format": "int64"
+        },
+        "running": {
+          "type": "boolean"
+        },
+        "since": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "Delivery": {
+      "description": "Delivery of the latest event of a post to a subscriber",
+      "type": "object",
+      "properties": {
+        "attempts": {
+          "description": "Failed attempts since the event was queued",
+          "type": "integer",
+          "format": "int64"
+        },
+        "delivered_at": {
+          "description": "Last time an event of the post reached the subscriber",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "event": {
+          "type": "string",
+          "enum": [
+            "new",
+            "updated",
+            "deleted"
+          ]
+        },
+        "last_error": {
+          "type": "string"
+        },
+        "next_attempt_time": {
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "status": {
+          "type": "string",
+          "enum": [
+            "pending",
+            "delivered",
+            "failed"
+          ]
+        },
+        "subscriber": {
+          "type": "string"
+        }
+      }
+    },
+    "DispatchAttempt": {
+      "description": "An attempt at sending a post to C-3PO",
+      "type": "object",
+      "properties": {
+        "dispatched_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "error": {
+          "description": "Why the attempt failed",
+          "type": "string"
+        },
+        "success": {
+          "type": "boolean"
+        }
+      }
+    },
+    "EngagementSnapshot": {
+      "description": "Reaction and comment totals of a post at a point in time",
+      "type": "object",
+      "properties": {
+        "comments": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "reactions": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "sampled_at": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "EngagementSnapshotList": {
+      "type": "object",
+      "properties": {
+        "snapshots": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/EngagementSnapshot"
+          }
+        }
+      }
+    },
+    "Error": {
+      "type": "object",
+      "required": [
+        "message"
+      ],
+      "properties": {
+        "message": {
+          "type": "string"
+        }
+      }
+    },
+    "MusicLink": {
+      "description": "A normalized link to a song, album, playlist or artist",
+      "type": "object",
+      "properties": {
+        "id": {
+          "description": "ID of the linked object within the provider, when the link carries one",
+          "type": "string"
+        },
+        "provider": {
+          "type": "string",
+          "enum": [
+            "youtube",
+            "spotify",
+            "soundcloud",
+            "apple_music",
+            "bandcamp"
+          ]
+        },
+        "source": {
+          "type": "string",
+          "enum": [
+            "link",
+            "message",
+            "comment"
+          ]
+        },
+        "url": {
+          "description": "Canonical link, without tracking parameters",
+          "type": "string"
+        }
+      }
+    },
+    "Post": {
+      "description": "A Facebook post stored by R2-D2",
+      "type": "object",
+      "properties": {
+        "created_time": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "dead_lettered": {
+          "description": "Whether delivering the post to C-3PO failed too many times to be retried automatically",
+          "type": "boolean"
+        },
+        "deleted_time": {
+          "description": "When the post was found to be deleted from the group, absent for live posts",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "deliveries": {
+          "description": "Delivery status of the latest event of the post for C-3PO and every webhook subscriber",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Delivery"
+          }
+        },
+        "dispatch_attempts": {
+          "description": "Failed attempts of the delivery to C-3PO since it was last queued",
+          "type": "integer",
+          "format": "int64"
+        },
+        "dispatch_history": {
+          "description": "The latest attempts to send the post to C-3PO, oldest first, up to 20",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/DispatchAttempt"
+          }
+        },
+        "facebook_id": {
+          "type": "string"
+        },
+        "is_parsed": {
+          "description": "Whether C-3PO has successfully parsed the post",
+          "type": "boolean"
+        },
+        "last_dispatch_error": {
+          "type": "string"
+        },
+        "links": {
+          "description": "Music links shared in the post and its comments",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/MusicLink"
+          }
+        },
+        "next_dispatch_time": {
+          "description": "Earliest time the dispatcher retries the post",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "post": {
+          "description": "The post as returned by the Graph API",
+          "type": "object"
+        },
+        "updated_time": {
+          "description": "Last time the post was edited, as of the latest fetch",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "PostList": {
+      "type": "object",
+      "properties": {
+        "next_cursor": {
+          "description": "Cursor for the next page, absent on the last page",
+          "type": "string"
+        },
+        "posts": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Post"
+          }
+        }
+      }
+    },
+    "RedispatchReport": {
+      "type": "object",
+      "properties": {
+        "results": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/RedispatchResult"
+          }
+        }
+      }
+    },
+    "RedispatchRequest": {
+      "type": "object",
+      "required": [
+        "created_after"
+      ],
+      "properties": {
+        "created_after": {
+          "description": "Redispatch posts created at or after this time",
+          "type": "string",
+          "format": "date-time"
+        },
+        "created_before": {
+          "description": "Redispatch posts created at or before this time",
+          "type": "string",
+          "format": "date-time"
+        },
+        "immediate": {
+          "description": "Dispatch the posts now instead of waiting for the dispatcher",
+          "type": "boolean"
+        }
+      }
+    },
+    "RedispatchResult": {
+      "type": "object",
+      "properties": {
+        "error": {
+          "description": "Why the redispatch failed",
+          "type": "string"
+        },
+        "facebook_id": {
+          "type": "string"
+        },
+        "status": {
+          "type": "string",
+          "enum": [
+            "queued",
+            "dispatched",
+            "failed"
+          ]
+        },
+        "subscriber": {
+          "description": "Subscriber the delivery is for, ` + "`" + `c3po` + "`" + ` for C-3PO",
+          "type": "string"
+        }
+      }
+    }
+  },
+  "securityDefinitions": {
+    "AdminToken": {
+      "description": "Shared secret set as ` + "`" + `ADMIN_TOKEN` + "`" + `. Admin endpoints answer 401 to every request while it's unset.",
+      "type": "apiKey",
+      "name": "X-Admin-Token",
+      "in": "header"
     }
   }
 }`))
@@ -90,6 +835,751 @@ func init() {
           }
         }
       }
+    },
+    "/v1/admin/backfill": {
+      "get": {
+        "summary": "Get the progress of the historical backfill",
+        "operationId": "getBackfill",
+        "responses": {
+          "200": {
+            "description": "Progress of the last backfill",
+            "schema": {
+              "$ref": "#/definitions/BackfillStatus"
+            }
+          },
+          "404": {
+            "description": "No backfill has been started yet",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Walks the whole group feed back to ` + "`" + `since` + "`" + ` in the background. A previous backfill with the same ` + "`" + `since` + "`" + ` that didn't finish is resumed from its last page.",
+        "summary": "Start a historical backfill of the group feed",
+        "operationId": "startBackfill",
+        "parameters": [
+          {
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/BackfillRequest"
+            }
+          }
+        ],
+        "responses": {
+          "202": {
+            "description": "Backfill started",
+            "schema": {
+              "$ref": "#/definitions/BackfillStatus"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "A backfill is already running",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts": {
+      "get": {
+        "description": "Returns a page of the Facebook posts stored by R2-D2. Pass ` + "`" + `next_cursor` + "`" + ` from the response as ` + "`" + `cursor` + "`" + ` to fetch the next page.",
+        "summary": "List stored posts",
+        "operationId": "listPosts",
+        "parameters": [
+          {
+            "maximum": 100,
+            "minimum": 1,
+            "type": "integer",
+            "format": "int64",
+            "default": 20,
+            "description": "Maximum number of posts in the page",
+            "name": "limit",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Opaque cursor returned as ` + "`" + `next_cursor` + "`" + ` by the previous page",
+            "name": "cursor",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return posts created at or after this time",
+            "name": "created_after",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return posts created at or before this time",
+            "name": "created_before",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "description": "Only return posts that have (or are waiting to be) parsed by C-3PO",
+            "name": "is_parsed",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Only return posts whose delivery to C-3PO ran out of attempts",
+            "name": "dead_lettered",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of stored posts",
+            "schema": {
+              "$ref": "#/definitions/PostList"
+            }
+          },
+          "400": {
+            "description": "Invalid cursor",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/dead-letters/replay": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Queues the deliveries to C-3PO and the webhook subscribers that ran out of attempts with a fresh attempt count, or attempts them right away when ` + "`" + `immediate` + "`" + ` is set.",
+        "summary": "Send every dead-lettered delivery again",
+        "operationId": "replayDeadLetters",
+        "parameters": [
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Attempt the deliveries now instead of waiting for the next run",
+            "name": "immediate",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the replay for every dead-lettered delivery",
+            "schema": {
+              "$ref": "#/definitions/RedispatchReport"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/redispatch": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "summary": "Send all posts in a time range to C-3PO again",
+        "operationId": "redispatchPosts",
+        "parameters": [
+          {
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/RedispatchRequest"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the redispatch for every matching post",
+            "schema": {
+              "$ref": "#/definitions/RedispatchReport"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}": {
+      "get": {
+        "description": "Returns the post as stored by R2-D2, along with its parse status and every attempt at dispatching it to C-3PO.",
+        "summary": "Get a stored post",
+        "operationId": "getPost",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The stored post",
+            "schema": {
+              "$ref": "#/definitions/Post"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}/engagement": {
+      "get": {
+        "description": "Totals are sampled on every fetch while the post is younger than ` + "`" + `ENGAGEMENT_SNAPSHOT_MAX_AGE_DAYS` + "`" + `.",
+        "summary": "Get the reaction and comment totals of a post over time",
+        "operationId": "listEngagementSnapshots",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Engagement samples, oldest first",
+            "schema": {
+              "$ref": "#/definitions/EngagementSnapshotList"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/v1/posts/{facebook_id}/redispatch": {
+      "post": {
+        "security": [
+          {
+            "AdminToken": []
+          }
+        ],
+        "description": "Queues the post for the next dispatcher run, or dispatches it right away when ` + "`" + `immediate` + "`" + ` is set.",
+        "summary": "Send a post to C-3PO again",
+        "operationId": "redispatchPost",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Graph API ID of the post",
+            "name": "facebook_id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Dispatch the post now instead of waiting for the dispatcher",
+            "name": "immediate",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Outcome of the redispatch",
+            "schema": {
+              "$ref": "#/definitions/RedispatchResult"
+            }
+          },
+          "401": {
+            "description": "Missing or invalid admin token",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Post not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to query the post store",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/webhooks/facebook": {
+      "get": {
+        "description": "Echoes ` + "`" + `hub.challenge` + "`" + ` back when ` + "`" + `hub.verify_token` + "`" + ` matches ` + "`" + `FB_WEBHOOK_VERIFY_TOKEN` + "`" + `.",
+        "produces": [
+          "text/plain"
+        ],
+        "summary": "Answer the subscription verification request of Facebook Webhooks",
+        "operationId": "verifyFacebookWebhook",
+        "parameters": [
+          {
+            "type": "string",
+            "name": "hub.mode",
+            "in": "query",
+            "required": true
+          },
+          {
+            "type": "string",
+            "name": "hub.verify_token",
+            "in": "query",
+            "required": true
+          },
+          {
+            "type": "string",
+            "name": "hub.challenge",
+            "in": "query",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The challenge",
+            "schema": {
+              "type": "string"
+            }
+          },
+          "403": {
+            "description": "Wrong mode or verify token"
+          }
+        }
+      },
+      "post": {
+        "description": "Stores every post the notification mentions right away, or tombstones it when it was removed. The body is the raw notification, signed with the app secret.",
+        "summary": "Receive a Facebook Webhooks notification for the group feed",
+        "operationId": "receiveFacebookWebhook",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "HMAC-SHA256 of the body with the app secret, as ` + "`" + `sha256=\u003chex digest\u003e` + "`" + `",
+            "name": "X-Hub-Signature-256",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Notification processed"
+          },
+          "400": {
+            "description": "Malformed notification",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "Missing or invalid signature",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "BackfillRequest": {
+      "type": "object",
+      "required": [
+        "since"
+      ],
+      "properties": {
+        "since": {
+          "description": "Backfill posts created at or after this time",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "BackfillStatus": {
+      "type": "object",
+      "properties": {
+        "done": {
+          "description": "Whether the backfill reached ` + "`" + `since` + "`" + ` or the end of the feed",
+          "type": "boolean"
+        },
+        "error": {
+          "description": "Why the last run failed",
+          "type": "string"
+        },
+        "posts_fetched": {
+          "description": "Number of posts stored so far",
+          "type": "integer",
+          "format": "int64"
+        },
+        "running": {
+          "type": "boolean"
+        },
+        "since": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "Delivery": {
+      "description": "Delivery of the latest event of a post to a subscriber",
+      "type": "object",
+      "properties": {
+        "attempts": {
+          "description": "Failed attempts since the event was queued",
+          "type": "integer",
+          "format": "int64"
+        },
+        "delivered_at": {
+          "description": "Last time an event of the post reached the subscriber",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "event": {
+          "type": "string",
+          "enum": [
+            "new",
+            "updated",
+            "deleted"
+          ]
+        },
+        "last_error": {
+          "type": "string"
+        },
+        "next_attempt_time": {
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "status": {
+          "type": "string",
+          "enum": [
+            "pending",
+            "delivered",
+            "failed"
+          ]
+        },
+        "subscriber": {
+          "type": "string"
+        }
+      }
+    },
+    "DispatchAttempt": {
+      "description": "An attempt at sending a post to C-3PO",
+      "type": "object",
+      "properties": {
+        "dispatched_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "error": {
+          "description": "Why the attempt failed",
+          "type": "string"
+        },
+        "success": {
+          "type": "boolean"
+        }
+      }
+    },
+    "EngagementSnapshot": {
+      "description": "Reaction and comment totals of a post at a point in time",
+      "type": "object",
+      "properties": {
+        "comments": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "reactions": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "sampled_at": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "EngagementSnapshotList": {
+      "type": "object",
+      "properties": {
+        "snapshots": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/EngagementSnapshot"
+          }
+        }
+      }
+    },
+    "Error": {
+      "type": "object",
+      "required": [
+        "message"
+      ],
+      "properties": {
+        "message": {
+          "type": "string"
+        }
+      }
+    },
+    "MusicLink": {
+      "description": "A normalized link to a song, album, playlist or artist",
+      "type": "object",
+      "properties": {
+        "id": {
+          "description": "ID of the linked object within the provider, when the link carries one",
+          "type": "string"
+        },
+        "provider": {
+          "type": "string",
+          "enum": [
+            "youtube",
+            "spotify",
+            "soundcloud",
+            "apple_music",
+            "bandcamp"
+          ]
+        },
+        "source": {
+          "type": "string",
+          "enum": [
+            "link",
+            "message",
+            "comment"
+          ]
+        },
+        "url": {
+          "description": "Canonical link, without tracking parameters",
+          "type": "string"
+        }
+      }
+    },
+    "Post": {
+      "description": "A Facebook post stored by R2-D2",
+      "type": "object",
+      "properties": {
+        "created_time": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "dead_lettered": {
+          "description": "Whether delivering the post to C-3PO failed too many times to be retried automatically",
+          "type": "boolean"
+        },
+        "deleted_time": {
+          "description": "When the post was found to be deleted from the group, absent for live posts",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "deliveries": {
+          "description": "Delivery status of the latest event of the post for C-3PO and every webhook subscriber",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Delivery"
+          }
+        },
+        "dispatch_attempts": {
+          "description": "Failed attempts of the delivery to C-3PO since it was last queued",
+          "type": "integer",
+          "format": "int64"
+        },
+        "dispatch_history": {
+          "description": "The latest attempts to send the post to C-3PO, oldest first, up to 20",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/DispatchAttempt"
+          }
+        },
+        "facebook_id": {
+          "type": "string"
+        },
+        "is_parsed": {
+          "description": "Whether C-3PO has successfully parsed the post",
+          "type": "boolean"
+        },
+        "last_dispatch_error": {
+          "type": "string"
+        },
+        "links": {
+          "description": "Music links shared in the post and its comments",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/MusicLink"
+          }
+        },
+        "next_dispatch_time": {
+          "description": "Earliest time the dispatcher retries the post",
+          "type": "string",
+          "format": "date-time",
+          "x-nullable": true
+        },
+        "post": {
+          "description": "The post as returned by the Graph API",
+          "type": "object"
+        },
+        "updated_time": {
+          "description": "Last time the post was edited, as of the latest fetch",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "PostList": {
+      "type": "object",
+      "properties": {
+        "next_cursor": {
+          "description": "Cursor for the next page, absent on the last page",
+          "type": "string"
+        },
+        "posts": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Post"
+          }
+        }
+      }
+    },
+    "RedispatchReport": {
+      "type": "object",
+      "properties": {
+        "results": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/RedispatchResult"
+          }
+        }
+      }
+    },
+    "RedispatchRequest": {
+      "type": "object",
+      "required": [
+        "created_after"
+      ],
+      "properties": {
+        "created_after": {
+          "description": "Redispatch posts created at or after this time",
+          "type": "string",
+          "format": "date-time"
+        },
+        "created_before": {
+          "description": "Redispatch posts created at or before this time",
+          "type": "string",
+          "format": "date-time"
+        },
+        "immediate": {
+          "description": "Dispatch the posts now instead of waiting for the dispatcher",
+          "type": "boolean"
+        }
+      }
+    },
+    "RedispatchResult": {
+      "type": "object",
+      "properties": {
+        "error": {
+          "description": "Why the redispatch failed",
+          "type": "string"
+        },
+        "facebook_id": {
+          "type": "string"
+        },
+        "status": {
+          "type": "string",
+          "enum": [
+            "queued",
+            "dispatched",
+            "failed"
+          ]
+        },
+        "subscriber": {
+          "description": "Subscriber the delivery is for, ` + "`" + `c3po` + "`" + ` for C-3PO",
+          "type": "string"
+        }
+      }
+    }
+  },
+  "securityDefinitions": {
+    "AdminToken": {
+      "description": "Shared secret set as ` + "`" + `ADMIN_TOKEN` + "`" + `. Admin endpoints answer 401 to every request while it's unset.",
+      "type": "apiKey",
+      "name": "X-Admin-Token",
+      "in": "header"
     }
   }
 }`))
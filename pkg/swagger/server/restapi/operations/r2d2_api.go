@@ -43,9 +43,46 @@ func NewR2d2API(spec *loads.Document) *R2d2API {
 		JSONProducer: runtime.JSONProducer(),
 		TxtProducer:  runtime.TextProducer(),
 
+		// Applies when the "X-Admin-Token" header is set
+		AdminTokenAuth: func(token string) (interface{}, error) {
+			return nil, errors.NotImplemented("api key auth (AdminToken) X-Admin-Token from header param [X-Admin-Token] has not yet been implemented")
+		},
+		// default authorizer is authorized meaning no requests are blocked
+		APIAuthorizer: security.Authorized(),
+
 		CheckHealthHandler: CheckHealthHandlerFunc(func(params CheckHealthParams) middleware.Responder {
 			return middleware.NotImplemented("operation CheckHealth has not yet been implemented")
 		}),
+		GetBackfillHandler: GetBackfillHandlerFunc(func(params GetBackfillParams) middleware.Responder {
+			return middleware.NotImplemented("operation GetBackfill has not yet been implemented")
+		}),
+		GetPostHandler: GetPostHandlerFunc(func(params GetPostParams) middleware.Responder {
+			return middleware.NotImplemented("operation GetPost has not yet been implemented")
+		}),
+		ListEngagementSnapshotsHandler: ListEngagementSnapshotsHandlerFunc(func(params ListEngagementSnapshotsParams) middleware.Responder {
+			return middleware.NotImplemented("operation ListEngagementSnapshots has not yet been implemented")
+		}),
+		ListPostsHandler: ListPostsHandlerFunc(func(params ListPostsParams) middleware.Responder {
+			return middleware.NotImplemented("operation ListPosts has not yet been implemented")
+		}),
+		ReceiveFacebookWebhookHandler: ReceiveFacebookWebhookHandlerFunc(func(params ReceiveFacebookWebhookParams) middleware.Responder {
+			return middleware.NotImplemented("operation ReceiveFacebookWebhook has not yet been implemented")
+		}),
+		RedispatchPostHandler: RedispatchPostHandlerFunc(func(params RedispatchPostParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation RedispatchPost has not yet been implemented")
+		}),
+		RedispatchPostsHandler: RedispatchPostsHandlerFunc(func(params RedispatchPostsParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation RedispatchPosts has not yet been implemented")
+		}),
+		ReplayDeadLettersHandler: ReplayDeadLettersHandlerFunc(func(params ReplayDeadLettersParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation ReplayDeadLetters has not yet been implemented")
+		}),
+		StartBackfillHandler: StartBackfillHandlerFunc(func(params StartBackfillParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation StartBackfill has not yet been implemented")
+		}),
+		VerifyFacebookWebhookHandler: VerifyFacebookWebhookHandlerFunc(func(params VerifyFacebookWebhookParams) middleware.Responder {
+			return middleware.NotImplemented("operation VerifyFacebookWebhook has not yet been implemented")
+		}),
 	}
 }
 
@@ -83,8 +120,35 @@ type R2d2API struct {
 	//   - text/plain
 	TxtProducer runtime.Producer
 
+	// AdminTokenAuth registers a function that takes a token and returns a principal
+	// it performs authentication based on an api key X-Admin-Token provided in the header
+	AdminTokenAuth func(string) (interface{}, error)
+
+	// APIAuthorizer provides access control (ACL/RBAC/ABAC) by providing access to the request and authenticated principal
+	APIAuthorizer runtime.Authorizer
+
 	// CheckHealthHandler sets the operation handler for the check health operation
 	CheckHealthHandler CheckHealthHandler
+	// GetBackfillHandler sets the operation handler for the get backfill operation
+	GetBackfillHandler GetBackfillHandler
+	// GetPostHandler sets the operation handler for the get post operation
+	GetPostHandler GetPostHandler
+	// ListEngagementSnapshotsHandler sets the operation handler for the list engagement snapshots operation
+	ListEngagementSnapshotsHandler ListEngagementSnapshotsHandler
+	// ListPostsHandler sets the operation handler for the list posts operation
+	ListPostsHandler ListPostsHandler
+	// ReceiveFacebookWebhookHandler sets the operation handler for the receive facebook webhook operation
+	ReceiveFacebookWebhookHandler ReceiveFacebookWebhookHandler
+	// RedispatchPostHandler sets the operation handler for the redispatch post operation
+	RedispatchPostHandler RedispatchPostHandler
+	// RedispatchPostsHandler sets the operation handler for the redispatch posts operation
+	RedispatchPostsHandler RedispatchPostsHandler
+	// ReplayDeadLettersHandler sets the operation handler for the replay dead letters operation
+	ReplayDeadLettersHandler ReplayDeadLettersHandler
+	// StartBackfillHandler sets the operation handler for the start backfill operation
+	StartBackfillHandler StartBackfillHandler
+	// VerifyFacebookWebhookHandler sets the operation handler for the verify facebook webhook operation
+	VerifyFacebookWebhookHandler VerifyFacebookWebhookHandler
 	// ServeError is called when an error is received, there is a default handler
 	// but you can set your own with this
 	ServeError func(http.ResponseWriter, *http.Request, error)
@@ -164,9 +228,43 @@ func (o *R2d2API) Validate() error {
 		unregistered = append(unregistered, "TxtProducer")
 	}
 
+	if o.AdminTokenAuth == nil {
+		unregistered = append(unregistered, "XAdminTokenAuth")
+	}
+
 	if o.CheckHealthHandler == nil {
 		unregistered = append(unregistered, "CheckHealthHandler")
 	}
+	if o.GetBackfillHandler == nil {
+		unregistered = append(unregistered, "GetBackfillHandler")
+	}
+	if o.GetPostHandler == nil {
+		unregistered = append(unregistered, "GetPostHandler")
+	}
+	if o.ListEngagementSnapshotsHandler == nil {
+		unregistered = append(unregistered, "ListEngagementSnapshotsHandler")
+	}
+	if o.ListPostsHandler == nil {
+		unregistered = append(unregistered, "ListPostsHandler")
+	}
+	if o.ReceiveFacebookWebhookHandler == nil {
+		unregistered = append(unregistered, "ReceiveFacebookWebhookHandler")
+	}
+	if o.RedispatchPostHandler == nil {
+		unregistered = append(unregistered, "RedispatchPostHandler")
+	}
+	if o.RedispatchPostsHandler == nil {
+		unregistered = append(unregistered, "RedispatchPostsHandler")
+	}
+	if o.ReplayDeadLettersHandler == nil {
+		unregistered = append(unregistered, "ReplayDeadLettersHandler")
+	}
+	if o.StartBackfillHandler == nil {
+		unregistered = append(unregistered, "StartBackfillHandler")
+	}
+	if o.VerifyFacebookWebhookHandler == nil {
+		unregistered = append(unregistered, "VerifyFacebookWebhookHandler")
+	}
 
 	if len(unregistered) > 0 {
 		return fmt.Errorf("missing registration: %s", strings.Join(unregistered, ", "))
@@ -182,12 +280,21 @@ func (o *R2d2API) ServeErrorFor(operationID string) func(http.ResponseWriter, *h
 
 // AuthenticatorsFor gets the authenticators for the specified security schemes
 func (o *R2d2API) AuthenticatorsFor(schemes map[string]spec.SecurityScheme) map[string]runtime.Authenticator {
-	return nil
+	result := make(map[string]runtime.Authenticator)
+	for name := range schemes {
+		switch name {
+		case "AdminToken":
+			scheme := schemes[name]
+			result[name] = o.APIKeyAuthenticator(scheme.Name, scheme.In, o.AdminTokenAuth)
+
+		}
+	}
+	return result
 }
 
 // Authorizer returns the registered authorizer
 func (o *R2d2API) Authorizer() runtime.Authorizer {
-	return nil
+	return o.APIAuthorizer
 }
 
 // ConsumersFor gets the consumers for the specified media types.
@@ -261,6 +368,46 @@ func (o *R2d2API) initHandlerCache() {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
 	o.handlers["GET"]["/healthz"] = NewCheckHealth(o.context, o.CheckHealthHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/v1/admin/backfill"] = NewGetBackfill(o.context, o.GetBackfillHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/v1/posts/{facebook_id}"] = NewGetPost(o.context, o.GetPostHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/v1/posts/{facebook_id}/engagement"] = NewListEngagementSnapshots(o.context, o.ListEngagementSnapshotsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/v1/posts"] = NewListPosts(o.context, o.ListPostsHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/webhooks/facebook"] = NewReceiveFacebookWebhook(o.context, o.ReceiveFacebookWebhookHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/v1/posts/{facebook_id}/redispatch"] = NewRedispatchPost(o.context, o.RedispatchPostHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/v1/posts/redispatch"] = NewRedispatchPosts(o.context, o.RedispatchPostsHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/v1/posts/dead-letters/replay"] = NewReplayDeadLetters(o.context, o.ReplayDeadLettersHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/v1/admin/backfill"] = NewStartBackfill(o.context, o.StartBackfillHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/webhooks/facebook"] = NewVerifyFacebookWebhook(o.context, o.VerifyFacebookWebhookHandler)
 }
 
 // Serve creates a http handler to serve the API over HTTP